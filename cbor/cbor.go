@@ -0,0 +1,140 @@
+// Package cbor implements a CBOR (RFC 8949) encoder and decoder with
+// first-class support for `nilo.Option`. It is built on reflection and the
+// standard library only.
+//
+// A `Nil` `Option` is encoded as the CBOR `null` simple value and a `Value`
+// `Option` as its wrapped value. Structs are encoded as maps keyed by field
+// name, which can be customized with the `cbor` struct tag:
+//
+//	type Reading struct {
+//		Sensor string               `cbor:"sensor"`
+//		Value  nilo.Option[float64] `cbor:"value"`
+//		Unit   nilo.Option[string]  `cbor:"unit,absent"`
+//		Note   nilo.Option[string]  `cbor:"note,omitempty"`
+//	}
+//
+// A `Nil` `Option` in a field tagged `absent` is encoded as `undefined`
+// instead of `null`, and a field tagged `omitempty` is left out when it holds
+// its zero value or a `Nil` `Option`. Both `null` and `undefined` decode into
+// a `Nil` `Option`.
+//
+// A `time.Time` is encoded as a standard date/time string (tag 0), and
+// decoded from it or from an epoch-based date/time (tag 1). Other values
+// implementing `encoding.TextMarshaler` are encoded as text strings, and
+// else those implementing `encoding.BinaryMarshaler` as byte strings, and
+// decoded with their unmarshaler. Structs with only unexported fields and
+// no marshaler cannot be encoded.
+//
+// `Marshal` always produces the core deterministic encoding described in
+// RFC 8949 section 4.2.1, so its output can be hashed or signed.
+package cbor
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+const tagName = "cbor"
+
+// Major types from RFC 8949 section 3.1.
+const (
+	majorUint byte = iota
+	majorNegInt
+	majorBytes
+	majorText
+	majorArray
+	majorMap
+	majorTag
+	majorSimple
+)
+
+// Simple values and additional information from RFC 8949 section 3.3.
+const (
+	simpleFalse     = 20
+	simpleTrue      = 21
+	simpleNull      = 22
+	simpleUndefined = 23
+	infoFloat16     = 25
+	infoFloat32     = 26
+	infoFloat64     = 27
+	infoIndefinite  = 31
+	breakByte       = 0xff
+)
+
+// SimpleValue is a CBOR simple value without a Go counterpart, such as
+// `undefined`. It is the type simple values decode to inside an `any`.
+type SimpleValue uint8
+
+// Undefined is the CBOR `undefined` simple value.
+const Undefined SimpleValue = simpleUndefined
+
+// Tag is a CBOR tagged data item. Tags decode into a `Tag` inside an `any`
+// and are skipped when decoding into any other type.
+type Tag struct {
+	Number  uint64
+	Content any
+}
+
+// ErrUnexpectedEnd is returned when the input ends in the middle of an item.
+var ErrUnexpectedEnd = errors.New("cbor: unexpected end of input")
+
+// UnsupportedTypeError is returned by `Marshal` and `Unmarshal` when a Go
+// type cannot be represented in CBOR.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return "cbor: unsupported type " + e.Type.String()
+}
+
+// UnmarshalTypeError describes a CBOR item that could not be stored in a Go
+// value of a specific type.
+type UnmarshalTypeError struct {
+	// Value is a description of the CBOR item, e.g. "text string".
+	Value string
+	// Type is the Go type it could not be assigned to.
+	Type reflect.Type
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	return fmt.Sprintf("cbor: cannot unmarshal %s into Go value of type %s", e.Value, e.Type)
+}
+
+// Marshal returns the core deterministic CBOR encoding of v.
+//
+// Integers, lengths and floating-point values use their shortest form,
+// every length is definite and map keys are sorted by their encoded bytes.
+func Marshal(v any) ([]byte, error) {
+	var e encoder
+	if err := e.encode(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+// Unmarshal parses the CBOR-encoded data and stores the result in the value
+// pointed to by v, which must be a non-nil pointer.
+//
+// Both definite and indefinite lengths are accepted. `null` and `undefined`
+// turn `Option`s `Nil` and other values into their zero value. Into an
+// `any`, items decode as `nil`, `bool`, `int64`, `uint64`, `float64`,
+// `[]byte`, `string`, `[]any`, `map[string]any` (or `map[any]any` for
+// non-text keys), `Tag` and `SimpleValue`.
+func Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("cbor: Unmarshal requires a non-nil pointer")
+	}
+
+	d := decoder{data: data}
+	item, err := d.item()
+	if err != nil {
+		return err
+	}
+	if d.off != len(d.data) {
+		return fmt.Errorf("cbor: %d bytes of trailing data", len(d.data)-d.off)
+	}
+	return assign(rv.Elem(), item)
+}
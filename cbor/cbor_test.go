@@ -0,0 +1,309 @@
+package cbor
+
+import (
+	"encoding/hex"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/javiorfo/nilo"
+	"github.com/stretchr/testify/assert"
+)
+
+// appendixA holds the RFC 8949 Appendix A examples whose encoding is the
+// preferred one, so decoding and re-encoding must give the same bytes.
+var appendixA = []string{
+	"00", "01", "0a", "17", "1818", "1819", "1864", "1903e8", "1a000f4240",
+	"1b000000e8d4a51000", "1bffffffffffffffff", "c249010000000000000000",
+	"c349010000000000000000", "20", "29", "3863", "3903e7",
+	"f90000", "f98000", "f93c00", "fb3ff199999999999a", "f93e00", "f97bff",
+	"fa47c35000", "fa7f7fffff", "fb7e37e43c8800759c", "f90001", "f90400",
+	"f9c400", "fbc010666666666666", "f97c00", "f97e00", "f9fc00",
+	"f4", "f5", "f6", "f7", "f0", "f8ff",
+	"c074323031332d30332d32315432303a30343a30305a", "c11a514b67b0",
+	"c1fb41d452d9ec200000", "d74401020304", "d818456449455446",
+	"d82076687474703a2f2f7777772e6578616d706c652e636f6d",
+	"40", "4401020304", "60", "6161", "6449455446", "62225c", "62c3bc",
+	"63e6b0b4", "64f0908591", "80", "83010203", "8301820203820405",
+	"98190102030405060708090a0b0c0d0e0f101112131415161718181819",
+	"a0", "a201020304", "a26161016162820203", "826161a161626163",
+	"a56161614161626142616361436164614461656145",
+}
+
+// appendixAEquivalents pairs the RFC 8949 Appendix A examples that use
+// non-preferred or indefinite length encodings with their preferred form.
+var appendixAEquivalents = [][2]string{
+	{"fa7f800000", "f97c00"},
+	{"fa7fc00000", "f97e00"},
+	{"faff800000", "f9fc00"},
+	{"fb7ff0000000000000", "f97c00"},
+	{"fb7ff8000000000000", "f97e00"},
+	{"fbfff0000000000000", "f9fc00"},
+	{"5f42010243030405ff", "450102030405"},
+	{"7f657374726561646d696e67ff", "6973747265616d696e67"},
+	{"9fff", "80"},
+	{"9f018202039f0405ffff", "8301820203820405"},
+	{"9f01820203820405ff", "8301820203820405"},
+	{"83018202039f0405ff", "8301820203820405"},
+	{"83019f0203ff820405", "8301820203820405"},
+	{"9f0102030405060708090a0b0c0d0e0f101112131415161718181819ff", "98190102030405060708090a0b0c0d0e0f101112131415161718181819"},
+	{"bf61610161629f0203ffff", "a26161016162820203"},
+	{"826161bf61626163ff", "826161a161626163"},
+	{"bf6346756ef563416d7421ff", "a263416d74216346756ef5"},
+}
+
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	assert.NoError(t, err)
+	return b
+}
+
+type reading struct {
+	Sensor string               `cbor:"sensor"`
+	Value  nilo.Option[float64] `cbor:"value"`
+	Unit   nilo.Option[string]  `cbor:"unit,absent"`
+	Note   nilo.Option[string]  `cbor:"note,omitempty"`
+	Tags   nilo.Option[[]int]   `cbor:"tags"`
+}
+
+func TestCBOR(t *testing.T) {
+	t.Run("Appendix A", func(t *testing.T) {
+		t.Run("preferred encodings round trip", func(t *testing.T) {
+			for _, vector := range appendixA {
+				var x any
+				err := Unmarshal(unhex(t, vector), &x)
+				assert.NoError(t, err, vector)
+
+				result, err := Marshal(x)
+				assert.NoError(t, err, vector)
+				assert.Equal(t, vector, hex.EncodeToString(result))
+			}
+		})
+
+		t.Run("other encodings decode like their preferred form", func(t *testing.T) {
+			for _, vector := range appendixAEquivalents {
+				var x any
+				err := Unmarshal(unhex(t, vector[0]), &x)
+				assert.NoError(t, err, vector[0])
+
+				result, err := Marshal(x)
+				assert.NoError(t, err, vector[0])
+				assert.Equal(t, vector[1], hex.EncodeToString(result))
+			}
+		})
+
+		t.Run("values below int64 fail", func(t *testing.T) {
+			var x any
+			err := Unmarshal(unhex(t, "3bffffffffffffffff"), &x)
+			assert.Error(t, err)
+
+			var n int64
+			err = Unmarshal(unhex(t, "3bffffffffffffffff"), &n)
+			assert.Error(t, err)
+		})
+	})
+
+	t.Run("Marshal", func(t *testing.T) {
+		t.Run("Nil Option encodes as null", func(t *testing.T) {
+			result, err := Marshal(nilo.Nil[int]())
+
+			assert.NoError(t, err)
+			assert.Equal(t, "f6", hex.EncodeToString(result))
+		})
+
+		t.Run("Value Option encodes its value", func(t *testing.T) {
+			result, err := Marshal(nilo.Value(1.5))
+
+			assert.NoError(t, err)
+			assert.Equal(t, "f93e00", hex.EncodeToString(result))
+		})
+
+		t.Run("absent fields encode Nil as undefined", func(t *testing.T) {
+			result, err := Marshal(reading{Sensor: "t1"})
+
+			assert.NoError(t, err)
+			// {"tags": null, "unit": undefined, "value": null, "sensor": "t1"}
+			assert.Equal(t, "a4"+"6474616773f6"+"64756e6974f7"+"6576616c7565f6"+"6673656e736f72627431", hex.EncodeToString(result))
+		})
+
+		t.Run("encoding is deterministic", func(t *testing.T) {
+			input := map[string]int{"bb": 2, "a": 1, "c": 3, "aa": 4}
+
+			first, err := Marshal(input)
+			assert.NoError(t, err)
+			for range 10 {
+				result, err := Marshal(input)
+				assert.NoError(t, err)
+				assert.Equal(t, first, result)
+			}
+			assert.Equal(t, "a46161016163036261610462626202", hex.EncodeToString(first))
+		})
+
+		t.Run("unsupported types return an error", func(t *testing.T) {
+			_, err := Marshal(func() {})
+
+			var unsupported *UnsupportedTypeError
+			assert.ErrorAs(t, err, &unsupported)
+
+			type secret struct{ token string }
+			_, err = Marshal(nilo.Value(secret{"x"}))
+			assert.ErrorAs(t, err, &unsupported)
+		})
+
+		t.Run("times encode as standard date/time strings", func(t *testing.T) {
+			result, err := Marshal(nilo.Value(time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC)))
+
+			assert.NoError(t, err)
+			assert.Equal(t, "c074323031332d30332d32315432303a30343a30305a", hex.EncodeToString(result))
+		})
+
+		t.Run("pointer cycles return an error", func(t *testing.T) {
+			type node struct {
+				Next *node
+			}
+			n := &node{}
+			n.Next = n
+
+			_, err := Marshal(n)
+
+			assert.ErrorContains(t, err, "exceeded max depth")
+		})
+	})
+
+	t.Run("Unmarshal", func(t *testing.T) {
+		t.Run("round trip of Value Options", func(t *testing.T) {
+			input := reading{
+				Sensor: "t1",
+				Value:  nilo.Value(21.5),
+				Unit:   nilo.Value("C"),
+				Note:   nilo.Value("ok"),
+				Tags:   nilo.Value([]int{-1, 1000}),
+			}
+			var result reading
+
+			data, err := Marshal(input)
+			assert.NoError(t, err)
+			err = Unmarshal(data, &result)
+
+			assert.NoError(t, err)
+			assert.Equal(t, input, result)
+		})
+
+		t.Run("round trip of marshalers", func(t *testing.T) {
+			type event struct {
+				At      time.Time              `cbor:"at"`
+				Updated nilo.Option[time.Time] `cbor:"updated"`
+				Addr    netip.Addr             `cbor:"addr"`
+			}
+			at := time.Date(2024, 1, 2, 3, 4, 5, 6, time.FixedZone("", 3600))
+			input := event{at, nilo.Value(at.Add(time.Hour)), netip.MustParseAddr("10.0.0.1")}
+			var result event
+
+			data, err := Marshal(input)
+			assert.NoError(t, err)
+			err = Unmarshal(data, &result)
+
+			assert.NoError(t, err)
+			assert.True(t, input.At.Equal(result.At))
+			assert.True(t, input.Updated.AsValue().Equal(result.Updated.AsValue()))
+			assert.Equal(t, input.Addr, result.Addr)
+		})
+
+		t.Run("epoch times decode", func(t *testing.T) {
+			var at time.Time
+			var fractional nilo.Option[time.Time]
+
+			assert.NoError(t, Unmarshal(unhex(t, "c11a514b67b0"), &at))
+			assert.NoError(t, Unmarshal(unhex(t, "c1fb41d452d9ec200000"), &fractional))
+
+			assert.Equal(t, time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC), at)
+			assert.Equal(t, time.Date(2013, 3, 21, 20, 4, 0, 5e8, time.UTC), fractional.AsValue())
+			assert.Error(t, Unmarshal(unhex(t, "c16161"), &at))
+		})
+
+		t.Run("null, undefined and absent fields become Nil", func(t *testing.T) {
+			result := reading{Value: nilo.Value(1.0), Unit: nilo.Value("C")}
+
+			data, err := Marshal(reading{Sensor: "t1"})
+			assert.NoError(t, err)
+			err = Unmarshal(data, &result)
+
+			assert.NoError(t, err)
+			assert.True(t, result.Value.IsNil())
+			assert.True(t, result.Unit.IsNil())
+			assert.True(t, result.Note.IsNil())
+		})
+
+		t.Run("tags are skipped for typed values", func(t *testing.T) {
+			var result nilo.Option[int64]
+
+			err := Unmarshal(unhex(t, "c11a514b67b0"), &result)
+
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1363896240), result.AsValue())
+		})
+
+		t.Run("type mismatch returns an error", func(t *testing.T) {
+			var result nilo.Option[int]
+
+			err := Unmarshal(unhex(t, "6161"), &result)
+
+			var typeErr *UnmarshalTypeError
+			assert.ErrorAs(t, err, &typeErr)
+			assert.True(t, result.IsNil())
+		})
+
+		t.Run("unhashable map keys return an error", func(t *testing.T) {
+			var m map[any]any
+
+			err := Unmarshal(unhex(t, "a180f6"), &m)
+
+			var typeErr *UnmarshalTypeError
+			assert.ErrorAs(t, err, &typeErr)
+		})
+
+		t.Run("malformed input returns an error", func(t *testing.T) {
+			var x any
+
+			assert.ErrorIs(t, Unmarshal(unhex(t, "9b00000000ffffffff"), &x), ErrUnexpectedEnd)
+			assert.Error(t, Unmarshal(unhex(t, "1c"), &x))
+			assert.Error(t, Unmarshal(unhex(t, "ff"), &x))
+			assert.Error(t, Unmarshal(unhex(t, "f818"), &x))
+			assert.Error(t, Unmarshal(unhex(t, "a1810001"), &x))
+		})
+
+		t.Run("integers decode into floats", func(t *testing.T) {
+			var f float32
+
+			err := Unmarshal(unhex(t, "3903e7"), &f)
+
+			assert.NoError(t, err)
+			assert.Equal(t, float32(-1000), f)
+		})
+	})
+}
+
+// FuzzUnmarshal checks that arbitrary input never makes the decoder panic
+// and that whatever decodes into an `any` re-encodes.
+func FuzzUnmarshal(f *testing.F) {
+	for _, vector := range appendixA {
+		b, _ := hex.DecodeString(vector)
+		f.Add(b)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var r reading
+		_ = Unmarshal(data, &r)
+
+		var x any
+		if Unmarshal(data, &x) == nil {
+			if _, err := Marshal(x); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		var m map[any]any
+		_ = Unmarshal(data, &m)
+	})
+}
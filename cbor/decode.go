@@ -0,0 +1,616 @@
+package cbor
+
+import (
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/javiorfo/nilo/internal/fields"
+	"github.com/javiorfo/nilo/internal/optreflect"
+)
+
+// maxDepth bounds the nesting of arrays, maps and tags to protect against
+// malicious input.
+const maxDepth = 10000
+
+// negInt holds a negative integer as its CBOR argument n, meaning -1-n, so
+// values below math.MinInt64 survive until assignment.
+type negInt uint64
+
+// pair is a decoded map entry. Maps keep their entries in a slice so that
+// keys which are not hashable in Go can still be reported properly.
+type pair struct {
+	key, value any
+}
+
+type decoder struct {
+	data  []byte
+	off   int
+	depth int
+}
+
+// item decodes the next data item into its intermediate representation:
+// nil, bool, uint64, negInt, float64, []byte, string, []any, []pair, Tag or
+// SimpleValue.
+func (d *decoder) item() (any, error) {
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case majorUint:
+		return arg, nil
+	case majorNegInt:
+		return negInt(arg), nil
+	case majorBytes, majorText:
+		b, err := d.bytes(major, info, arg)
+		if err != nil {
+			return nil, err
+		}
+		if major == majorText {
+			return string(b), nil
+		}
+		return b, nil
+	case majorArray:
+		return d.array(info, arg)
+	case majorMap:
+		return d.mapping(info, arg)
+	case majorTag:
+		if err := d.enter(); err != nil {
+			return nil, err
+		}
+		defer d.leave()
+		content, err := d.item()
+		if err != nil {
+			return nil, err
+		}
+		return Tag{Number: arg, Content: content}, nil
+	default:
+		return d.simple(info, arg)
+	}
+}
+
+// head reads the initial byte of an item and its argument.
+func (d *decoder) head() (major, info byte, arg uint64, err error) {
+	if d.off >= len(d.data) {
+		return 0, 0, 0, ErrUnexpectedEnd
+	}
+	b := d.data[d.off]
+	d.off++
+	major, info = b>>5, b&0x1f
+
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info <= 27:
+		size := 1 << (info - 24)
+		if size > len(d.data)-d.off {
+			return 0, 0, 0, ErrUnexpectedEnd
+		}
+		raw := d.data[d.off : d.off+size]
+		d.off += size
+		switch size {
+		case 1:
+			arg = uint64(raw[0])
+		case 2:
+			arg = uint64(binary.BigEndian.Uint16(raw))
+		case 4:
+			arg = uint64(binary.BigEndian.Uint32(raw))
+		default:
+			arg = binary.BigEndian.Uint64(raw)
+		}
+		return major, info, arg, nil
+	case info == infoIndefinite && major >= majorBytes && major != majorTag:
+		return major, info, 0, nil
+	default:
+		return 0, 0, 0, fmt.Errorf("cbor: malformed initial byte 0x%02x at offset %d", b, d.off-1)
+	}
+}
+
+// bytes reads the payload of a byte or text string, joining the chunks of
+// an indefinite length string.
+func (d *decoder) bytes(major, info byte, arg uint64) ([]byte, error) {
+	if info != infoIndefinite {
+		if arg > uint64(len(d.data)-d.off) {
+			return nil, ErrUnexpectedEnd
+		}
+		b := append([]byte{}, d.data[d.off:d.off+int(arg)]...)
+		d.off += int(arg)
+		return b, nil
+	}
+
+	b := []byte{}
+	for {
+		if d.atBreak() {
+			d.off++
+			return b, nil
+		}
+		m, i, n, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if m != major || i == infoIndefinite {
+			return nil, fmt.Errorf("cbor: invalid chunk in indefinite length string at offset %d", d.off)
+		}
+		chunk, err := d.bytes(m, i, n)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, chunk...)
+	}
+}
+
+func (d *decoder) array(info byte, arg uint64) (any, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer d.leave()
+
+	if info != infoIndefinite && arg > uint64(len(d.data)-d.off) {
+		return nil, ErrUnexpectedEnd
+	}
+
+	items := make([]any, 0, int(arg))
+	for i := uint64(0); info == infoIndefinite || i < arg; i++ {
+		if info == infoIndefinite && d.atBreak() {
+			d.off++
+			break
+		}
+		x, err := d.item()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, x)
+	}
+	return items, nil
+}
+
+func (d *decoder) mapping(info byte, arg uint64) (any, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer d.leave()
+
+	if info != infoIndefinite && arg > uint64(len(d.data)-d.off)/2 {
+		return nil, ErrUnexpectedEnd
+	}
+
+	pairs := make([]pair, 0, int(arg))
+	for i := uint64(0); info == infoIndefinite || i < arg; i++ {
+		if info == infoIndefinite && d.atBreak() {
+			d.off++
+			break
+		}
+		k, err := d.item()
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.item()
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, pair{k, v})
+	}
+	return pairs, nil
+}
+
+func (d *decoder) simple(info byte, arg uint64) (any, error) {
+	switch info {
+	case simpleFalse, simpleTrue:
+		return info == simpleTrue, nil
+	case simpleNull:
+		return nil, nil
+	case 24:
+		if arg < 32 {
+			return nil, fmt.Errorf("cbor: invalid two-byte simple value %d at offset %d", arg, d.off-2)
+		}
+		return SimpleValue(arg), nil
+	case infoFloat16:
+		return halfToFloat(uint16(arg)), nil
+	case infoFloat32:
+		return float64(math.Float32frombits(uint32(arg))), nil
+	case infoFloat64:
+		return math.Float64frombits(arg), nil
+	case infoIndefinite:
+		return nil, fmt.Errorf("cbor: unexpected break at offset %d", d.off-1)
+	default:
+		return SimpleValue(info), nil
+	}
+}
+
+func (d *decoder) atBreak() bool {
+	return d.off < len(d.data) && d.data[d.off] == breakByte
+}
+
+func (d *decoder) enter() error {
+	d.depth++
+	if d.depth > maxDepth {
+		return fmt.Errorf("cbor: exceeded max depth of %d", maxDepth)
+	}
+	return nil
+}
+
+func (d *decoder) leave() {
+	d.depth--
+}
+
+// assign stores the intermediate item x in the settable value v.
+func assign(v reflect.Value, x any) error {
+	t := v.Type()
+	if x == nil || (x == Undefined && v.Kind() != reflect.Interface) {
+		v.Set(reflect.Zero(t))
+		return nil
+	}
+
+	if optreflect.Is(t) {
+		inner := reflect.New(optreflect.Elem(t)).Elem()
+		if err := assign(inner, x); err != nil {
+			return err
+		}
+		optreflect.Set(v, inner)
+		return nil
+	}
+
+	switch t {
+	case reflect.TypeFor[Tag]():
+		if tag, ok := x.(Tag); ok {
+			content, err := toAny(tag.Content)
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(Tag{tag.Number, content}))
+			return nil
+		}
+	case reflect.TypeFor[SimpleValue]():
+		if s, ok := x.(SimpleValue); ok {
+			v.SetUint(uint64(s))
+			return nil
+		}
+	}
+
+	if tag, ok := x.(Tag); ok && tag.Number == tagEpoch && t == timeType {
+		return assignEpoch(v, tag.Content)
+	}
+	if tag, ok := x.(Tag); ok && v.Kind() != reflect.Interface {
+		return assign(v, tag.Content)
+	}
+
+	if ok, err := assignUnmarshaler(v, x); ok {
+		return err
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		return assign(v.Elem(), x)
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return &UnsupportedTypeError{t}
+		}
+		a, err := toAny(x)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(a))
+		return nil
+	case reflect.Bool:
+		if b, ok := x.(bool); ok {
+			v.SetBool(b)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch n := x.(type) {
+		case uint64:
+			if n <= math.MaxInt64 && !v.OverflowInt(int64(n)) {
+				v.SetInt(int64(n))
+				return nil
+			}
+		case negInt:
+			if n <= math.MaxInt64 && !v.OverflowInt(-1-int64(n)) {
+				v.SetInt(-1 - int64(n))
+				return nil
+			}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n, ok := x.(uint64); ok && !v.OverflowUint(n) {
+			v.SetUint(n)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		switch n := x.(type) {
+		case float64:
+			v.SetFloat(n)
+			return nil
+		case uint64:
+			v.SetFloat(float64(n))
+			return nil
+		case negInt:
+			v.SetFloat(-1 - float64(n))
+			return nil
+		}
+	case reflect.String:
+		if s, ok := x.(string); ok {
+			v.SetString(s)
+			return nil
+		}
+	case reflect.Slice:
+		if b, ok := x.([]byte); ok && t.Elem().Kind() == reflect.Uint8 {
+			v.SetBytes(b)
+			return nil
+		}
+		if items, ok := x.([]any); ok {
+			v.Set(reflect.MakeSlice(t, len(items), len(items)))
+			for i, item := range items {
+				if err := assign(v.Index(i), item); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case reflect.Array:
+		if b, ok := x.([]byte); ok && t.Elem().Kind() == reflect.Uint8 {
+			v.SetZero()
+			reflect.Copy(v, reflect.ValueOf(b))
+			return nil
+		}
+		if items, ok := x.([]any); ok {
+			v.SetZero()
+			for i := 0; i < len(items) && i < v.Len(); i++ {
+				if err := assign(v.Index(i), items[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case reflect.Map:
+		if pairs, ok := x.([]pair); ok {
+			if v.IsNil() {
+				v.Set(reflect.MakeMapWithSize(t, len(pairs)))
+			}
+			for _, p := range pairs {
+				key := reflect.New(t.Key()).Elem()
+				if err := assign(key, p.key); err != nil {
+					return err
+				}
+				if !hashable(key.Interface()) {
+					return &UnmarshalTypeError{Value: describe(p.key) + " map key", Type: t}
+				}
+				elem := reflect.New(t.Elem()).Elem()
+				if err := assign(elem, p.value); err != nil {
+					return err
+				}
+				v.SetMapIndex(key, elem)
+			}
+			return nil
+		}
+	case reflect.Struct:
+		if pairs, ok := x.([]pair); ok {
+			fs := fields.Of(t, tagName)
+			for _, p := range pairs {
+				name, ok := p.key.(string)
+				if !ok {
+					continue
+				}
+				f, ok := fields.ByName(fs, name)
+				if !ok {
+					continue
+				}
+				if err := assign(v.FieldByIndex(f.Index), p.value); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	default:
+		return &UnsupportedTypeError{t}
+	}
+
+	return &UnmarshalTypeError{Value: describe(x), Type: t}
+}
+
+// assignUnmarshaler decodes a text string with `encoding.TextUnmarshaler`
+// and a byte string with `encoding.BinaryUnmarshaler`, or else
+// `encoding.TextUnmarshaler`, when v implements them, and reports whether
+// it did.
+func assignUnmarshaler(v reflect.Value, x any) (bool, error) {
+	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface || !v.CanAddr() {
+		return false, nil
+	}
+	text, isText := v.Addr().Interface().(encoding.TextUnmarshaler)
+	bin, isBinary := v.Addr().Interface().(encoding.BinaryUnmarshaler)
+
+	var err error
+	switch x := x.(type) {
+	case string:
+		if !isText {
+			return false, nil
+		}
+		err = text.UnmarshalText([]byte(x))
+	case []byte:
+		switch {
+		case isBinary:
+			err = bin.UnmarshalBinary(x)
+		case isText:
+			err = text.UnmarshalText(x)
+		default:
+			return false, nil
+		}
+	default:
+		return false, nil
+	}
+	if err != nil {
+		return true, fmt.Errorf("cbor: decoding %s: %w", v.Type(), err)
+	}
+	return true, nil
+}
+
+// assignEpoch stores the content of an epoch-based date/time tag, a number
+// of seconds since 1970-01-01T00:00Z, in the `time.Time` v.
+func assignEpoch(v reflect.Value, x any) error {
+	var t time.Time
+	switch n := x.(type) {
+	case uint64:
+		if n > math.MaxInt64 {
+			return &UnmarshalTypeError{Value: "epoch " + describe(x), Type: timeType}
+		}
+		t = time.Unix(int64(n), 0)
+	case negInt:
+		if n > math.MaxInt64 {
+			return &UnmarshalTypeError{Value: "epoch " + describe(x), Type: timeType}
+		}
+		t = time.Unix(-1-int64(n), 0)
+	case float64:
+		if math.IsNaN(n) || n < math.MinInt64 || n >= math.MaxInt64 {
+			return &UnmarshalTypeError{Value: "epoch " + describe(x), Type: timeType}
+		}
+		sec := math.Floor(n)
+		t = time.Unix(int64(sec), int64((n-sec)*1e9))
+	default:
+		return &UnmarshalTypeError{Value: "epoch " + describe(x), Type: timeType}
+	}
+	v.Set(reflect.ValueOf(t.UTC()))
+	return nil
+}
+
+// toAny converts an intermediate item into its `any` representation.
+func toAny(x any) (any, error) {
+	switch n := x.(type) {
+	case uint64:
+		if n <= math.MaxInt64 {
+			return int64(n), nil
+		}
+		return n, nil
+	case negInt:
+		if n > math.MaxInt64 {
+			return nil, &UnmarshalTypeError{Value: "negative integer", Type: reflect.TypeFor[int64]()}
+		}
+		return -1 - int64(n), nil
+	case []any:
+		items := make([]any, len(n))
+		for i, item := range n {
+			a, err := toAny(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = a
+		}
+		return items, nil
+	case []pair:
+		return pairsToAny(n)
+	case Tag:
+		content, err := toAny(n.Content)
+		if err != nil {
+			return nil, err
+		}
+		return Tag{n.Number, content}, nil
+	default:
+		return x, nil
+	}
+}
+
+func pairsToAny(pairs []pair) (any, error) {
+	keys := make([]any, len(pairs))
+	textKeys := true
+	for i, p := range pairs {
+		k, err := toAny(p.key)
+		if err != nil {
+			return nil, err
+		}
+		if !hashable(k) {
+			return nil, &UnmarshalTypeError{Value: describe(p.key) + " map key", Type: reflect.TypeFor[map[any]any]()}
+		}
+		if _, ok := k.(string); !ok {
+			textKeys = false
+		}
+		keys[i] = k
+	}
+
+	if textKeys {
+		m := make(map[string]any, len(pairs))
+		for i, p := range pairs {
+			v, err := toAny(p.value)
+			if err != nil {
+				return nil, err
+			}
+			m[keys[i].(string)] = v
+		}
+		return m, nil
+	}
+
+	m := make(map[any]any, len(pairs))
+	for i, p := range pairs {
+		v, err := toAny(p.value)
+		if err != nil {
+			return nil, err
+		}
+		m[keys[i]] = v
+	}
+	return m, nil
+}
+
+func hashable(k any) bool {
+	switch k := k.(type) {
+	case []byte, []any, map[string]any, map[any]any:
+		return false
+	case Tag:
+		return hashable(k.Content)
+	case nil:
+		return true
+	default:
+		return reflect.ValueOf(k).Comparable()
+	}
+}
+
+func halfToFloat(h uint16) float64 {
+	sign := 1.0
+	if h&0x8000 != 0 {
+		sign = -1
+	}
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+
+	switch exp {
+	case 0:
+		return sign * math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			return math.Inf(int(sign))
+		}
+		return math.NaN()
+	default:
+		return sign * math.Ldexp(mant+1024, exp-25)
+	}
+}
+
+func describe(x any) string {
+	switch x.(type) {
+	case bool:
+		return "bool"
+	case uint64:
+		return "unsigned integer"
+	case negInt:
+		return "negative integer"
+	case float64:
+		return "float"
+	case []byte:
+		return "byte string"
+	case string:
+		return "text string"
+	case []any:
+		return "array"
+	case []pair:
+		return "map"
+	case Tag:
+		return "tag"
+	case SimpleValue:
+		return "simple value"
+	default:
+		return fmt.Sprintf("%T", x)
+	}
+}
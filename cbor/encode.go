@@ -0,0 +1,364 @@
+package cbor
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/javiorfo/nilo/internal/fields"
+	"github.com/javiorfo/nilo/internal/optreflect"
+)
+
+// Tag numbers from RFC 8949 section 3.4.
+const (
+	tagDateTime = 0
+	tagEpoch    = 1
+)
+
+var (
+	timeType            = reflect.TypeFor[time.Time]()
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	binaryMarshalerType = reflect.TypeFor[encoding.BinaryMarshaler]()
+)
+
+type encoder struct {
+	buf   []byte
+	depth int
+}
+
+type entry struct {
+	key, value []byte
+}
+
+func (e *encoder) encode(v reflect.Value) error {
+	if !v.IsValid() {
+		e.writeSimple(simpleNull)
+		return nil
+	}
+
+	if err := e.enter(); err != nil {
+		return err
+	}
+	defer e.leave()
+
+	t := v.Type()
+	if optreflect.Is(t) {
+		inner, ok := optreflect.Get(v)
+		if !ok {
+			e.writeSimple(simpleNull)
+			return nil
+		}
+		return e.encode(inner)
+	}
+
+	switch t {
+	case reflect.TypeFor[Tag]():
+		tag := v.Interface().(Tag)
+		e.writeHead(majorTag, tag.Number)
+		return e.encode(reflect.ValueOf(tag.Content))
+	case reflect.TypeFor[SimpleValue]():
+		e.writeSimple(byte(v.Uint()))
+		return nil
+	case timeType:
+		text, err := v.Interface().(time.Time).MarshalText()
+		if err != nil {
+			return err
+		}
+		e.writeHead(majorTag, tagDateTime)
+		e.writeHead(majorText, uint64(len(text)))
+		e.buf = append(e.buf, text...)
+		return nil
+	}
+
+	if ok, err := e.encodeMarshaler(v); ok {
+		return err
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			e.writeSimple(simpleTrue)
+		} else {
+			e.writeSimple(simpleFalse)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i := v.Int(); i < 0 {
+			e.writeHead(majorNegInt, uint64(-1-i))
+		} else {
+			e.writeHead(majorUint, uint64(i))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.writeHead(majorUint, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		e.writeFloat(v.Float())
+	case reflect.String:
+		e.writeHead(majorText, uint64(v.Len()))
+		e.buf = append(e.buf, v.String()...)
+	case reflect.Slice:
+		if v.IsNil() {
+			e.writeSimple(simpleNull)
+			return nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			e.writeHead(majorBytes, uint64(v.Len()))
+			e.buf = append(e.buf, v.Bytes()...)
+			return nil
+		}
+		return e.encodeArray(v)
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			e.writeHead(majorBytes, uint64(len(b)))
+			e.buf = append(e.buf, b...)
+			return nil
+		}
+		return e.encodeArray(v)
+	case reflect.Map:
+		if v.IsNil() {
+			e.writeSimple(simpleNull)
+			return nil
+		}
+		return e.encodeMap(v)
+	case reflect.Struct:
+		return e.encodeStruct(v)
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			e.writeSimple(simpleNull)
+			return nil
+		}
+		return e.encode(v.Elem())
+	default:
+		return &UnsupportedTypeError{t}
+	}
+	return nil
+}
+
+func (e *encoder) encodeArray(v reflect.Value) error {
+	e.writeHead(majorArray, uint64(v.Len()))
+	for i := range v.Len() {
+		if err := e.encode(v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *encoder) encodeMap(v reflect.Value) error {
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		ke, ve := encoder{depth: e.depth}, encoder{depth: e.depth}
+		if err := ke.encode(iter.Key()); err != nil {
+			return err
+		}
+		if err := ve.encode(iter.Value()); err != nil {
+			return err
+		}
+		entries = append(entries, entry{ke.buf, ve.buf})
+	}
+	e.writeEntries(entries)
+	return nil
+}
+
+// encodeMarshaler writes v as a text string when it implements
+// `encoding.TextMarshaler`, or else as a byte string when it implements
+// `encoding.BinaryMarshaler`, and reports whether it did.
+func (e *encoder) encodeMarshaler(v reflect.Value) (bool, error) {
+	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		return false, nil
+	}
+	if !v.Type().Implements(textMarshalerType) && !v.Type().Implements(binaryMarshalerType) {
+		if !v.CanAddr() {
+			return false, nil
+		}
+		v = v.Addr()
+	}
+
+	switch m := v.Interface().(type) {
+	case encoding.TextMarshaler:
+		text, err := m.MarshalText()
+		if err != nil {
+			return true, err
+		}
+		e.writeHead(majorText, uint64(len(text)))
+		e.buf = append(e.buf, text...)
+		return true, nil
+	case encoding.BinaryMarshaler:
+		data, err := m.MarshalBinary()
+		if err != nil {
+			return true, err
+		}
+		e.writeHead(majorBytes, uint64(len(data)))
+		e.buf = append(e.buf, data...)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (e *encoder) encodeStruct(v reflect.Value) error {
+	fs := fields.Of(v.Type(), tagName)
+	if len(fs) == 0 && !exported(v.Type()) {
+		return &UnsupportedTypeError{v.Type()}
+	}
+
+	entries := make([]entry, 0, len(fs))
+	for _, f := range fs {
+		fv := v.FieldByIndex(f.Index)
+		if f.Has("omitempty") && optreflect.IsEmpty(fv) {
+			continue
+		}
+
+		ke, ve := encoder{depth: e.depth}, encoder{depth: e.depth}
+		ke.writeHead(majorText, uint64(len(f.Name)))
+		ke.buf = append(ke.buf, f.Name...)
+		if f.Has("absent") && isNilOption(fv) {
+			ve.writeSimple(simpleUndefined)
+		} else if err := ve.encode(fv); err != nil {
+			return err
+		}
+		entries = append(entries, entry{ke.buf, ve.buf})
+	}
+	e.writeEntries(entries)
+	return nil
+}
+
+// exported reports whether t has no fields or an exported one. Encoding a
+// struct whose data is all unexported, like a `time.Time` without its
+// marshaler, as an empty map would lose it silently.
+func exported(t reflect.Type) bool {
+	for i := range t.NumField() {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return t.NumField() == 0
+}
+
+// enter bounds the nesting of values, which is endless for pointer
+// cycles.
+func (e *encoder) enter() error {
+	e.depth++
+	if e.depth > maxDepth {
+		return fmt.Errorf("cbor: exceeded max depth of %d", maxDepth)
+	}
+	return nil
+}
+
+func (e *encoder) leave() {
+	e.depth--
+}
+
+// writeEntries writes a map sorted by the bytewise lexicographic order of
+// its encoded keys.
+func (e *encoder) writeEntries(entries []entry) {
+	slices.SortFunc(entries, func(a, b entry) int { return bytes.Compare(a.key, b.key) })
+
+	e.writeHead(majorMap, uint64(len(entries)))
+	for _, en := range entries {
+		e.buf = append(e.buf, en.key...)
+		e.buf = append(e.buf, en.value...)
+	}
+}
+
+// writeHead writes the initial byte and argument of an item in its
+// shortest form.
+func (e *encoder) writeHead(major byte, n uint64) {
+	m := major << 5
+	switch {
+	case n < 24:
+		e.buf = append(e.buf, m|byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, m|24, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, m|25)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	case n <= math.MaxUint32:
+		e.buf = append(e.buf, m|26)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	default:
+		e.buf = append(e.buf, m|27)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, n)
+	}
+}
+
+func (e *encoder) writeSimple(s byte) {
+	if s < 24 {
+		e.buf = append(e.buf, majorSimple<<5|s)
+		return
+	}
+	e.buf = append(e.buf, majorSimple<<5|24, s)
+}
+
+// writeFloat writes f in the shortest of the half, single and double
+// precision forms that preserves its value. NaN is always written as the
+// canonical half precision quiet NaN.
+func (e *encoder) writeFloat(f float64) {
+	if math.IsNaN(f) {
+		e.buf = append(e.buf, majorSimple<<5|infoFloat16, 0x7e, 0x00)
+		return
+	}
+	if h, ok := toHalf(f); ok {
+		e.buf = append(e.buf, majorSimple<<5|infoFloat16)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, h)
+		return
+	}
+	if float64(float32(f)) == f {
+		e.buf = append(e.buf, majorSimple<<5|infoFloat32)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, math.Float32bits(float32(f)))
+		return
+	}
+	e.buf = append(e.buf, majorSimple<<5|infoFloat64)
+	e.buf = binary.BigEndian.AppendUint64(e.buf, math.Float64bits(f))
+}
+
+// toHalf converts f to IEEE 754 half precision if that is lossless.
+func toHalf(f float64) (uint16, bool) {
+	f32 := float32(f)
+	if float64(f32) != f {
+		return 0, false
+	}
+
+	bits := math.Float32bits(f32)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23) & 0xff
+	mant := bits & 0x7fffff
+
+	switch {
+	case exp == 0xff:
+		return sign | 0x7c00, true
+	case exp == 0 && mant == 0:
+		return sign, true
+	}
+
+	e := exp - 127
+	switch {
+	case e >= -14 && e <= 15:
+		if mant&0x1fff != 0 {
+			return 0, false
+		}
+		return sign | uint16(e+15)<<10 | uint16(mant>>13), true
+	case e >= -24 && e < -14:
+		m := mant | 0x800000
+		shift := uint(-14 - e + 13)
+		if m&(1<<shift-1) != 0 {
+			return 0, false
+		}
+		return sign | uint16(m>>shift), true
+	default:
+		return 0, false
+	}
+}
+
+func isNilOption(v reflect.Value) bool {
+	if !optreflect.Is(v.Type()) {
+		return false
+	}
+	_, ok := optreflect.Get(v)
+	return !ok
+}
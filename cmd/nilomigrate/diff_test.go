@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func lines(from, to int) string {
+	var sb strings.Builder
+	for i := from; i <= to; i++ {
+		fmt.Fprintf(&sb, "line %d\n", i)
+	}
+	return sb.String()
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	t.Run("equal", func(t *testing.T) {
+		assert.Empty(t, unifiedDiff("a.go", []byte(lines(1, 5)), []byte(lines(1, 5))))
+	})
+
+	t.Run("one change", func(t *testing.T) {
+		old := lines(1, 10)
+		new := strings.Replace(old, "line 5\n", "line five\n", 1)
+
+		assert.Equal(t, `--- a/a.go
++++ b/a.go
+@@ -2,7 +2,7 @@
+ line 2
+ line 3
+ line 4
+-line 5
++line five
+ line 6
+ line 7
+ line 8
+`, unifiedDiff("a.go", []byte(old), []byte(new)))
+	})
+
+	t.Run("distant changes", func(t *testing.T) {
+		old := lines(1, 20)
+		new := strings.Replace(strings.Replace(old, "line 2\n", "", 1), "line 18\n", "line 18\nextra\n", 1)
+
+		assert.Equal(t, `--- a/a.go
++++ b/a.go
+@@ -1,5 +1,4 @@
+ line 1
+-line 2
+ line 3
+ line 4
+ line 5
+@@ -16,5 +15,6 @@
+ line 16
+ line 17
+ line 18
++extra
+ line 19
+ line 20
+`, unifiedDiff("a.go", []byte(old), []byte(new)))
+	})
+
+	t.Run("close changes share a hunk", func(t *testing.T) {
+		old := lines(1, 12)
+		new := strings.Replace(strings.Replace(old, "line 3\n", "three\n", 1), "line 9\n", "nine\n", 1)
+
+		diff := unifiedDiff("a.go", []byte(old), []byte(new))
+
+		assert.Equal(t, 1, strings.Count(diff, "@@ -"))
+		assert.Contains(t, diff, "@@ -1,12 +1,12 @@\n")
+	})
+
+	t.Run("new file content", func(t *testing.T) {
+		assert.Equal(t, "--- a/a.go\n+++ b/a.go\n@@ -0,0 +1,2 @@\n+line 1\n+line 2\n", unifiedDiff("a.go", nil, []byte(lines(1, 2))))
+	})
+}
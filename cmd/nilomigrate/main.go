@@ -0,0 +1,249 @@
+// Command nilomigrate migrates optional values written as pointers to
+// `nilo.Option`.
+//
+// It rewrites the selected struct fields and function results from `*T` to
+// `nilo.Option[T]`, and updates their use sites in the package:
+//
+//	x != nil      →  x.IsValue()
+//	x == nil      →  x.IsNil()
+//	*x            →  x.AsValue()
+//	*x = v        →  x = nilo.Value(v)
+//	*x += v       →  *x.AsPtr() += v
+//	x = &v        →  x = nilo.Value(v)
+//	x = nil       →  x = nilo.Nil[T]()
+//	x = p         →  x = nilo.Ptr(p)
+//	other uses    →  x.AsPtr()
+//
+// Variables defined from the results of a migrated function are migrated
+// too. Assignments that cannot be migrated safely are reported on standard
+// error for manual review.
+//
+// Usage:
+//
+//	nilomigrate [-w] [-field Type.Field]... [-func Func|Type.Method]... [dir]
+//
+// By default, a unified diff of the changes is printed so they can be
+// reviewed; -w writes them to the files instead.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// list is a flag accepting several values, repeated or separated by commas.
+type list []string
+
+func (l *list) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *list) Set(s string) error {
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+func main() {
+	var t targets
+	write := flag.Bool("w", false, "write the changes to the files instead of printing a diff")
+	flag.Var((*list)(&t.fields), "field", "struct field to migrate, as Type.Field")
+	flag.Var((*list)(&t.funcs), "func", "function whose pointer results to migrate, as Func or Type.Method")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: nilomigrate [-w] [-field Type.Field]... [-func Func|Type.Method]... [dir]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	} else if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+	if len(t.fields) == 0 && len(t.funcs) == 0 {
+		fmt.Fprintln(os.Stderr, "nilomigrate: nothing to migrate, use -field or -func")
+		os.Exit(2)
+	}
+
+	changes, warnings, err := migrate(dir, t)
+	for _, w := range warnings {
+		fmt.Fprintln(os.Stderr, w)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "nilomigrate:", err)
+		os.Exit(1)
+	}
+
+	for _, c := range changes {
+		if *write {
+			if err := os.WriteFile(c.path, c.new, 0o644); err != nil {
+				fmt.Fprintln(os.Stderr, "nilomigrate:", err)
+				os.Exit(1)
+			}
+			continue
+		}
+		name := c.path
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, c.path); err == nil {
+				name = rel
+			}
+		}
+		fmt.Print(unifiedDiff(filepath.ToSlash(name), c.old, c.new))
+	}
+}
+
+// change is the new content of a migrated file.
+type change struct {
+	path     string
+	old, new []byte
+}
+
+// migrate rewrites the packages in dir, the package itself and its tests,
+// and returns the files that change.
+func migrate(dir string, t targets) ([]change, []string, error) {
+	fset := token.NewFileSet()
+	pkgs, names, err := parseDir(fset, dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(names) == 0 {
+		return nil, nil, fmt.Errorf("no Go files in %s", dir)
+	}
+	// The package itself goes first, so its declarations are resolved
+	// before its external tests use them.
+	sort.SliceStable(names, func(i, j int) bool {
+		return !strings.HasSuffix(names[i], "_test") && strings.HasSuffix(names[j], "_test")
+	})
+
+	path := importPath(dir)
+	imp := importer.ForCompiler(fset, "source", nil)
+	m := &migration{
+		fset:   fset,
+		fields: map[string]types.Type{},
+		funcs:  map[string][]result{},
+		vars:   map[string]types.Type{},
+	}
+
+	var changes []change
+	for i, name := range names {
+		pkgPath := path
+		if strings.HasSuffix(name, "_test") {
+			pkgPath += "_test"
+		}
+		var typeErr error
+		conf := types.Config{
+			Importer: imp,
+			Error: func(err error) {
+				if typeErr == nil {
+					typeErr = err
+				}
+			},
+		}
+		m.info = &types.Info{
+			Types:      map[ast.Expr]types.TypeAndValue{},
+			Defs:       map[*ast.Ident]types.Object{},
+			Uses:       map[*ast.Ident]types.Object{},
+			Selections: map[*ast.SelectorExpr]*types.Selection{},
+		}
+		m.pkg, _ = conf.Check(pkgPath, fset, pkgs[name], m.info)
+		if typeErr != nil {
+			return nil, m.warnings, typeErr
+		}
+		if i == 0 {
+			if err := m.resolve(m.pkg, t); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		for _, f := range pkgs[name] {
+			filename := fset.Position(f.Pos()).Filename
+			old, err := os.ReadFile(filename)
+			if err != nil {
+				return nil, nil, err
+			}
+			src, err := m.rewrite(f)
+			if err != nil {
+				return nil, nil, err
+			}
+			if src != nil {
+				changes = append(changes, change{filename, old, src})
+			}
+		}
+	}
+	return changes, m.warnings, nil
+}
+
+// parseDir parses the Go files of dir matching the build constraints and
+// groups them by package name.
+func parseDir(fset *token.FileSet, dir string) (map[string][]*ast.File, []string, error) {
+	// Files are parsed by absolute name, like the source importer does
+	// for the external tests, so the positions of declarations match.
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	pkgs := map[string][]*ast.File{}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") {
+			continue
+		}
+		if ok, err := build.Default.MatchFile(dir, e.Name()); err != nil || !ok {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, e.Name()), nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, ok := pkgs[f.Name.Name]; !ok {
+			names = append(names, f.Name.Name)
+		}
+		pkgs[f.Name.Name] = append(pkgs[f.Name.Name], f)
+	}
+	return pkgs, names, nil
+}
+
+// importPath returns the import path of the package in dir from the
+// module path of its go.mod file, or dir itself outside a module.
+func importPath(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+	for root := abs; ; {
+		data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+		if err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if v, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+					rel, _ := filepath.Rel(root, abs)
+					return filepath.ToSlash(filepath.Join(strings.Trim(strings.TrimSpace(v), `"`), rel))
+				}
+			}
+			return dir
+		}
+		parent := filepath.Dir(root)
+		if parent == root {
+			return dir
+		}
+		root = parent
+	}
+}
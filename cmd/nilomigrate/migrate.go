@@ -0,0 +1,678 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const niloPath = "github.com/javiorfo/nilo"
+
+// targets are the declarations to migrate, named as in the command line:
+// "Type.Field" for struct fields and "Func" or "Type.Method" for function
+// results.
+type targets struct {
+	fields []string
+	funcs  []string
+}
+
+// result is a migrated function result.
+type result struct {
+	index int
+	elem  types.Type
+}
+
+// migration rewrites the files of one package. Declarations are identified
+// by the position of their object, so they match between the package and
+// the copy imported by its external tests.
+type migration struct {
+	fset     *token.FileSet
+	info     *types.Info
+	pkg      *types.Package
+	fields   map[string]types.Type // field position → element type
+	funcs    map[string][]result   // function position → migrated results
+	vars     map[string]types.Type // variable position → element type
+	warnings []string
+
+	file     *ast.File
+	nilo     string
+	changed  bool
+	usesNilo bool
+	returns  []result
+	nresults int
+}
+
+// key identifies an object by its declaration position.
+func (m *migration) key(obj types.Object) string {
+	if obj == nil || !obj.Pos().IsValid() {
+		return ""
+	}
+	return m.fset.Position(obj.Pos()).String()
+}
+
+// resolve finds the selected declarations in the scope of pkg.
+func (m *migration) resolve(pkg *types.Package, t targets) error {
+	for _, name := range t.fields {
+		typeName, fieldName, ok := strings.Cut(name, ".")
+		if !ok {
+			return fmt.Errorf("field %q is not of the form Type.Field", name)
+		}
+		obj, ok := pkg.Scope().Lookup(typeName).(*types.TypeName)
+		if !ok {
+			return fmt.Errorf("type %s not found in package %s", typeName, pkg.Name())
+		}
+		st, ok := obj.Type().Underlying().(*types.Struct)
+		if !ok {
+			return fmt.Errorf("type %s is not a struct", typeName)
+		}
+		var field *types.Var
+		for i := range st.NumFields() {
+			if st.Field(i).Name() == fieldName {
+				field = st.Field(i)
+			}
+		}
+		if field == nil {
+			return fmt.Errorf("field %s not found in %s", fieldName, typeName)
+		}
+		ptr, ok := field.Type().(*types.Pointer)
+		if !ok {
+			return fmt.Errorf("field %s is not a pointer", name)
+		}
+		m.fields[m.key(field)] = ptr.Elem()
+	}
+
+	for _, name := range t.funcs {
+		var fn *types.Func
+		if typeName, method, ok := strings.Cut(name, "."); ok {
+			obj, ok := pkg.Scope().Lookup(typeName).(*types.TypeName)
+			if !ok {
+				return fmt.Errorf("type %s not found in package %s", typeName, pkg.Name())
+			}
+			if sel := types.NewMethodSet(types.NewPointer(obj.Type())).Lookup(pkg, method); sel != nil {
+				fn, _ = sel.Obj().(*types.Func)
+			}
+		} else {
+			fn, _ = pkg.Scope().Lookup(name).(*types.Func)
+		}
+		if fn == nil {
+			return fmt.Errorf("function %s not found in package %s", name, pkg.Name())
+		}
+
+		var results []result
+		sig := fn.Type().(*types.Signature)
+		for i := range sig.Results().Len() {
+			v := sig.Results().At(i)
+			if ptr, ok := v.Type().(*types.Pointer); ok {
+				results = append(results, result{i, ptr.Elem()})
+				if v.Name() != "" && v.Name() != "_" {
+					m.vars[m.key(v)] = ptr.Elem()
+				}
+			}
+		}
+		if len(results) == 0 {
+			return fmt.Errorf("function %s has no pointer results", name)
+		}
+		m.funcs[m.key(fn)] = results
+	}
+	return nil
+}
+
+// rewrite migrates f and returns its new source, or nil if it is unchanged.
+func (m *migration) rewrite(f *ast.File) ([]byte, error) {
+	m.file, m.changed, m.usesNilo, m.returns = f, false, false, nil
+	m.nilo = "nilo"
+	for _, imp := range f.Imports {
+		if path, _ := strconv.Unquote(imp.Path.Value); path == niloPath && imp.Name != nil {
+			m.nilo = imp.Name.Name
+		}
+	}
+
+	m.declarations(f)
+	m.walk(f)
+	if !m.changed {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	cfg := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+	if err := cfg.Fprint(&buf, m.fset, f); err != nil {
+		return nil, err
+	}
+	if !m.usesNilo {
+		return buf.Bytes(), nil
+	}
+	return addImport(buf.Bytes())
+}
+
+// declarations changes the types of the migrated fields and results, and
+// records the variables holding migrated results.
+func (m *migration) declarations(f *ast.File) {
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch d := n.(type) {
+		case *ast.StructType:
+			d.Fields.List = m.fieldList(d.Fields.List, func(id *ast.Ident) bool {
+				_, ok := m.fields[m.key(m.info.Defs[id])]
+				return ok
+			})
+		case *ast.FuncDecl:
+			results, ok := m.funcs[m.key(m.info.Defs[d.Name])]
+			if !ok || d.Type.Results == nil {
+				return true
+			}
+			index := 0
+			for _, field := range d.Type.Results.List {
+				n := max(1, len(field.Names))
+				for _, r := range results {
+					if r.index >= index && r.index < index+n {
+						field.Type = m.optionType(field.Type)
+						m.changed = true
+						break
+					}
+				}
+				index += n
+			}
+		case *ast.AssignStmt:
+			if d.Tok == token.DEFINE {
+				m.resultVars(d.Lhs, d.Rhs)
+			}
+		case *ast.ValueSpec:
+			names := make([]ast.Expr, len(d.Names))
+			for i, id := range d.Names {
+				names[i] = id
+			}
+			m.resultVars(names, d.Values)
+		}
+		return true
+	})
+}
+
+// fieldList changes the type of the fields selected by migrated, splitting
+// declarations like `A, B *string` when only some names are selected.
+func (m *migration) fieldList(list []*ast.Field, migrated func(*ast.Ident) bool) []*ast.Field {
+	var out []*ast.Field
+	for _, field := range list {
+		var keep, move []*ast.Ident
+		for _, id := range field.Names {
+			if migrated(id) {
+				move = append(move, id)
+			} else {
+				keep = append(keep, id)
+			}
+		}
+		if len(move) == 0 {
+			out = append(out, field)
+			continue
+		}
+		m.changed = true
+		migratedField := &ast.Field{Doc: field.Doc, Names: move, Type: m.optionType(field.Type), Tag: field.Tag, Comment: field.Comment}
+		if len(keep) == 0 {
+			out = append(out, migratedField)
+			continue
+		}
+		field.Names = keep
+		if move[0].Pos() < keep[0].Pos() {
+			out = append(out, migratedField, field)
+		} else {
+			out = append(out, field, migratedField)
+		}
+	}
+	return out
+}
+
+// resultVars records the variables defined from a call to a migrated
+// function.
+func (m *migration) resultVars(lhs, rhs []ast.Expr) {
+	if len(rhs) != 1 {
+		return
+	}
+	call, ok := rhs[0].(*ast.CallExpr)
+	if !ok {
+		return
+	}
+	for _, r := range m.funcs[m.key(m.callee(call))] {
+		if r.index >= len(lhs) {
+			continue
+		}
+		if id, ok := lhs[r.index].(*ast.Ident); ok && m.info.Defs[id] != nil {
+			m.vars[m.key(m.info.Defs[id])] = r.elem
+		}
+	}
+}
+
+// walk calls edit on the expressions and statements below n, storing the
+// nodes it returns in their place.
+func (m *migration) walk(n ast.Node) {
+	v := reflect.ValueOf(n).Elem()
+	for i := range v.NumField() {
+		f := v.Field(i)
+		switch f.Kind() {
+		case reflect.Interface, reflect.Pointer:
+			m.slot(f)
+		case reflect.Slice:
+			for j := range f.Len() {
+				m.slot(f.Index(j))
+			}
+		}
+	}
+}
+
+func (m *migration) slot(f reflect.Value) {
+	if f.IsNil() {
+		return
+	}
+	child, ok := f.Interface().(ast.Node)
+	if !ok {
+		return
+	}
+	if f.Kind() != reflect.Interface {
+		m.walk(child)
+		return
+	}
+	if repl := m.edit(child); repl != child {
+		f.Set(reflect.ValueOf(repl))
+	}
+}
+
+func (m *migration) expr(e ast.Expr) ast.Expr {
+	return m.edit(e).(ast.Expr)
+}
+
+// edit returns the migrated version of n, walking its children.
+func (m *migration) edit(n ast.Node) ast.Node {
+	switch e := n.(type) {
+	case *ast.FuncDecl:
+		returns, nresults := m.returns, m.nresults
+		m.returns, m.nresults = m.funcs[m.key(m.info.Defs[e.Name])], e.Type.Results.NumFields()
+		m.walk(e)
+		m.returns, m.nresults = returns, nresults
+		return e
+	case *ast.FuncLit:
+		returns := m.returns
+		m.returns = nil
+		m.walk(e)
+		m.returns = returns
+		return e
+	case *ast.ReturnStmt:
+		if m.returns == nil || len(e.Results) != m.nresults {
+			m.walk(e)
+			return e
+		}
+		for i, x := range e.Results {
+			e.Results[i] = m.expr(x)
+			for _, r := range m.returns {
+				if r.index == i {
+					e.Results[i] = m.wrap(x, r.elem)
+				}
+			}
+		}
+		return e
+	case *ast.AssignStmt:
+		m.assign(e)
+		return e
+	case *ast.IncDecStmt:
+		if star, ok := e.X.(*ast.StarExpr); ok {
+			if _, ok := m.target(star.X); ok {
+				star.X = m.call(star.X, "AsPtr")
+				return e
+			}
+		}
+	case *ast.ValueSpec:
+		for i, x := range e.Values {
+			if _, ok := m.target(x); ok && len(e.Values) == 1 {
+				m.walk(x)
+				continue
+			}
+			e.Values[i] = m.expr(x)
+		}
+		return e
+	case *ast.CompositeLit:
+		m.compositeLit(e)
+		return e
+	case *ast.BinaryExpr:
+		if e.Op != token.EQL && e.Op != token.NEQ {
+			break
+		}
+		method := map[token.Token]string{token.EQL: "IsNil", token.NEQ: "IsValue"}[e.Op]
+		if _, ok := m.target(e.X); ok && m.isNil(e.Y) {
+			return m.call(e.X, method)
+		}
+		if _, ok := m.target(e.Y); ok && m.isNil(e.X) {
+			return m.call(e.Y, method)
+		}
+	case *ast.StarExpr:
+		if _, ok := m.target(e.X); ok {
+			return m.call(e.X, "AsValue")
+		}
+	case ast.Expr:
+		if _, ok := m.target(e); ok {
+			return m.call(e, "AsPtr")
+		}
+	}
+	m.walk(n)
+	return n
+}
+
+// assign migrates assignments to targets and from migrated functions.
+func (m *migration) assign(s *ast.AssignStmt) {
+	if len(s.Lhs) != len(s.Rhs) {
+		if call, ok := s.Rhs[0].(*ast.CallExpr); ok {
+			for _, r := range m.funcs[m.key(m.callee(call))] {
+				if lhs := s.Lhs[r.index]; !m.migratedVar(lhs) && !isBlank(lhs) {
+					m.warn(s.Pos(), "results of %s assigned to variables that are not migrated; review manually", types.ExprString(call.Fun))
+				}
+			}
+			m.walk(call)
+		}
+		for i, lhs := range s.Lhs {
+			if _, ok := m.target(lhs); ok {
+				m.walk(lhs)
+				continue
+			}
+			s.Lhs[i] = m.expr(lhs)
+		}
+		return
+	}
+
+	for i, lhs := range s.Lhs {
+		if star, ok := lhs.(*ast.StarExpr); ok {
+			if _, ok := m.target(star.X); ok && s.Tok == token.ASSIGN {
+				m.walk(star.X)
+				s.Lhs[i] = star.X
+				s.Rhs[i] = m.value(m.expr(s.Rhs[i]))
+				continue
+			} else if ok {
+				star.X = m.call(star.X, "AsPtr")
+				s.Rhs[i] = m.expr(s.Rhs[i])
+				continue
+			}
+		}
+		if elem, ok := m.target(lhs); ok {
+			m.walk(lhs)
+			s.Rhs[i] = m.wrap(s.Rhs[i], elem)
+			continue
+		}
+		if s.Tok == token.DEFINE {
+			if m.migratedVar(lhs) {
+				m.walk(s.Rhs[i])
+				continue
+			}
+		} else {
+			s.Lhs[i] = m.expr(lhs)
+		}
+		s.Rhs[i] = m.expr(s.Rhs[i])
+	}
+}
+
+// compositeLit wraps the values of migrated fields in struct literals.
+func (m *migration) compositeLit(lit *ast.CompositeLit) {
+	var st *types.Struct
+	if t := m.info.TypeOf(lit); t != nil {
+		if p, ok := t.Underlying().(*types.Pointer); ok {
+			t = p.Elem()
+		}
+		st, _ = t.Underlying().(*types.Struct)
+	}
+
+	for i, elt := range lit.Elts {
+		var field types.Object
+		value := &lit.Elts[i]
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if id, ok := kv.Key.(*ast.Ident); ok && st != nil {
+				field = m.info.Uses[id]
+			}
+			value = &kv.Value
+		} else if st != nil && i < st.NumFields() {
+			field = st.Field(i)
+		}
+
+		if elem, ok := m.fields[m.key(field)]; ok && st != nil {
+			*value = m.wrap(*value, elem)
+		} else {
+			*value = m.expr(*value)
+		}
+	}
+}
+
+// target reports whether e is a migrated field, variable or function call,
+// and returns the element type of its `Option`.
+func (m *migration) target(e ast.Expr) (types.Type, bool) {
+	switch x := ast.Unparen(e).(type) {
+	case *ast.SelectorExpr:
+		if s, ok := m.info.Selections[x]; ok && s.Kind() == types.FieldVal {
+			elem, ok := m.fields[m.key(s.Obj())]
+			return elem, ok
+		}
+	case *ast.Ident:
+		elem, ok := m.vars[m.key(m.info.Uses[x])]
+		return elem, ok
+	case *ast.CallExpr:
+		results := m.funcs[m.key(m.callee(x))]
+		if len(results) == 1 && m.info.TypeOf(x) != nil {
+			if _, tuple := m.info.TypeOf(x).(*types.Tuple); !tuple {
+				return results[0].elem, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// migratedVar reports whether e is a variable holding a migrated result.
+func (m *migration) migratedVar(e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	obj := m.info.Defs[id]
+	if obj == nil {
+		obj = m.info.Uses[id]
+	}
+	_, ok = m.vars[m.key(obj)]
+	return ok
+}
+
+// wrap converts e, a pointer expression, into an `Option` of elem.
+func (m *migration) wrap(e ast.Expr, elem types.Type) ast.Expr {
+	m.changed = true
+	switch x := ast.Unparen(e).(type) {
+	case *ast.UnaryExpr:
+		if x.Op == token.AND {
+			return m.value(m.expr(x.X))
+		}
+	case *ast.Ident:
+		if m.isNil(x) {
+			return at(&ast.CallExpr{Fun: &ast.IndexExpr{X: m.niloSel("Nil"), Index: m.typeExpr(elem)}}, x.Pos())
+		}
+	}
+	if _, ok := m.target(e); ok {
+		m.walk(e)
+		return e
+	}
+	return m.niloCall("Ptr", e, m.expr(e))
+}
+
+func (m *migration) value(e ast.Expr) ast.Expr {
+	return m.niloCall("Value", e, e)
+}
+
+// niloCall returns the call of the nilo function name with arg, placed
+// where orig was.
+func (m *migration) niloCall(name string, orig, arg ast.Expr) ast.Expr {
+	m.changed = true
+	call := at(&ast.CallExpr{Fun: m.niloSel(name)}, orig.Pos())
+	call.Args = []ast.Expr{arg}
+	call.Rparen = orig.End()
+	return call
+}
+
+// call returns the call of method on the target e.
+func (m *migration) call(e ast.Expr, method string) ast.Expr {
+	m.changed = true
+	m.walk(e)
+	call := at(&ast.CallExpr{Fun: &ast.SelectorExpr{Sel: ast.NewIdent(method)}}, e.End())
+	call.Fun.(*ast.SelectorExpr).X = e
+	return call
+}
+
+func (m *migration) niloSel(name string) ast.Expr {
+	m.usesNilo = true
+	return &ast.SelectorExpr{X: ast.NewIdent(m.nilo), Sel: ast.NewIdent(name)}
+}
+
+// optionType turns the pointer type expression t into an `Option` type.
+func (m *migration) optionType(t ast.Expr) ast.Expr {
+	pos := t.Pos()
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+	option := at(&ast.IndexExpr{X: m.niloSel("Option")}, pos)
+	option.Index = t
+	option.Rbrack = t.End()
+	return option
+}
+
+// at sets every position of the new node n to pos, so the printer keeps it
+// on the line of the code it replaces.
+func at[N ast.Node](n N, pos token.Pos) N {
+	ast.Inspect(n, func(n ast.Node) bool {
+		if n == nil {
+			return false
+		}
+		v := reflect.ValueOf(n).Elem()
+		for i := range v.NumField() {
+			if f := v.Field(i); f.Type() == reflect.TypeFor[token.Pos]() && v.Type().Field(i).Name != "Ellipsis" {
+				f.SetInt(int64(pos))
+			}
+		}
+		return true
+	})
+	return n
+}
+
+// typeExpr returns the expression of t as written in the current file.
+func (m *migration) typeExpr(t types.Type) ast.Expr {
+	s := types.TypeString(t, func(p *types.Package) string {
+		if p == m.pkg {
+			return ""
+		}
+		for _, imp := range m.file.Imports {
+			if path, _ := strconv.Unquote(imp.Path.Value); path == p.Path() && imp.Name != nil {
+				return imp.Name.Name
+			}
+		}
+		return p.Name()
+	})
+	e, err := parser.ParseExpr(s)
+	if err != nil {
+		return ast.NewIdent(s)
+	}
+	return e
+}
+
+func (m *migration) callee(call *ast.CallExpr) types.Object {
+	switch f := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		return m.info.Uses[f]
+	case *ast.SelectorExpr:
+		if s, ok := m.info.Selections[f]; ok {
+			return s.Obj()
+		}
+		return m.info.Uses[f.Sel]
+	}
+	return nil
+}
+
+func (m *migration) isNil(e ast.Expr) bool {
+	id, ok := ast.Unparen(e).(*ast.Ident)
+	if !ok {
+		return false
+	}
+	_, isNil := m.info.Uses[id].(*types.Nil)
+	return isNil
+}
+
+func (m *migration) warn(pos token.Pos, format string, args ...any) {
+	m.warnings = append(m.warnings, fmt.Sprintf("%s: %s", m.fset.Position(pos), fmt.Sprintf(format, args...)))
+}
+
+func isBlank(e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == "_"
+}
+
+// addImport adds the nilo import to the source of a file unless it is
+// already there. It joins the last import group when that one holds
+// non-standard packages, and starts a group of its own otherwise.
+func addImport(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+	for _, imp := range f.Imports {
+		if path, _ := strconv.Unquote(imp.Path.Value); path == niloPath {
+			return src, nil
+		}
+	}
+
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+	spec := strconv.Quote(niloPath)
+	var last *ast.GenDecl
+	for _, decl := range f.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			last = gen
+		}
+	}
+
+	var out bytes.Buffer
+	switch {
+	case last == nil:
+		end := offset(f.Name.End())
+		out.Write(src[:end])
+		fmt.Fprintf(&out, "\n\nimport %s", spec)
+		out.Write(src[end:])
+	case last.Lparen.IsValid() && len(last.Specs) > 0 && !isStd(last.Specs[len(last.Specs)-1]):
+		// Insert before the first import sorting after nilo in the last
+		// group, at the start of its line.
+		at := offset(last.Rparen)
+		for i := len(last.Specs) - 1; i >= 0; i-- {
+			imp := last.Specs[i].(*ast.ImportSpec)
+			if i < len(last.Specs)-1 && fset.Position(imp.End()).Line+1 < fset.Position(last.Specs[i+1].Pos()).Line {
+				break
+			}
+			if path, _ := strconv.Unquote(imp.Path.Value); path < niloPath {
+				break
+			}
+			at = offset(imp.Pos())
+		}
+		at = bytes.LastIndexByte(src[:at], '\n') + 1
+		out.Write(src[:at])
+		fmt.Fprintf(&out, "\t%s\n", spec)
+		out.Write(src[at:])
+	case last.Lparen.IsValid():
+		rparen := offset(last.Rparen)
+		out.Write(src[:rparen])
+		fmt.Fprintf(&out, "\n\t%s\n", spec)
+		out.Write(src[rparen:])
+	default:
+		start, end := offset(last.Pos()), offset(last.End())
+		out.Write(src[:start])
+		fmt.Fprintf(&out, "import (\n\t%s\n\n\t%s\n)", src[offset(last.Specs[0].Pos()):end], spec)
+		out.Write(src[end:])
+	}
+	return out.Bytes(), nil
+}
+
+// isStd reports whether the import is of a standard library package, whose
+// paths have no dot in their first element.
+func isStd(spec ast.Spec) bool {
+	path, _ := strconv.Unquote(spec.(*ast.ImportSpec).Path.Value)
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
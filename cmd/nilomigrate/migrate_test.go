@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+var cases = []struct {
+	dir      string
+	targets  targets
+	warnings []string
+}{
+	{
+		"users",
+		targets{fields: []string{"User.Email", "User.Age"}, funcs: []string{"Find", "Lookup"}},
+		[]string{"users.go:94:2: results of Find assigned to variables that are not migrated; review manually"},
+	},
+	{"repo", targets{fields: []string{"Item.Price"}, funcs: []string{"Repo.Get"}}, nil},
+	{"noimports", targets{fields: []string{"Config.Retries"}}, nil},
+}
+
+// golden compares got with the content of the golden file, or rewrites it
+// with -update.
+func golden(t *testing.T, path string, got []byte) {
+	t.Helper()
+	if *update {
+		assert.NoError(t, os.WriteFile(path, got, 0o644))
+		return
+	}
+	want, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestMigrate(t *testing.T) {
+	for _, c := range cases {
+		t.Run(c.dir, func(t *testing.T) {
+			dir := filepath.Join("testdata", c.dir)
+			changes, warnings, err := migrate(dir, c.targets)
+
+			assert.NoError(t, err)
+			assert.Len(t, warnings, len(c.warnings))
+			for i, w := range c.warnings {
+				assert.Contains(t, warnings[i], filepath.Join(dir, w))
+			}
+			assert.NotEmpty(t, changes)
+			for _, change := range changes {
+				golden(t, change.path+".golden", change.new)
+			}
+		})
+	}
+
+	t.Run("invalid targets", func(t *testing.T) {
+		dir := filepath.Join("testdata", "users")
+		for _, tt := range []targets{
+			{fields: []string{"Email"}},
+			{fields: []string{"Missing.Email"}},
+			{fields: []string{"User.Missing"}},
+			{fields: []string{"User.Name"}},
+			{funcs: []string{"Missing"}},
+			{funcs: []string{"User.Missing"}},
+			{funcs: []string{"Domain"}},
+		} {
+			_, _, err := migrate(dir, tt)
+			assert.Error(t, err, tt)
+		}
+	})
+}
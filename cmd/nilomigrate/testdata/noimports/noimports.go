@@ -0,0 +1,14 @@
+package noimports
+
+// Config holds optional settings.
+type Config struct {
+	Retries *int
+}
+
+// Retries returns the configured retries or a default.
+func Retries(c Config) int {
+	if c.Retries != nil {
+		return *c.Retries
+	}
+	return 3
+}
@@ -0,0 +1,44 @@
+package repo
+
+import opt "github.com/javiorfo/nilo"
+
+// Item is a stored item.
+type Item struct {
+	Name  string
+	Price *float64
+	Tags  opt.Option[[]string]
+}
+
+// Repo stores items by name.
+type Repo struct {
+	items map[string]Item
+}
+
+// Get returns the item with the given name, or nil.
+func (r *Repo) Get(name string) (item *Item) {
+	if i, ok := r.items[name]; ok {
+		item = &i
+	}
+	return
+}
+
+// Price returns the price of the named item, or 0.
+func (r *Repo) Price(name string) float64 {
+	item := r.Get(name)
+	if item == nil || item.Price == nil {
+		return 0
+	}
+	return *item.Price
+}
+
+// Discount lowers the price of every item.
+func (r *Repo) Discount(by float64) {
+	for name, item := range r.items {
+		if item.Price != nil {
+			*item.Price -= by
+		}
+		p := item.Price
+		item.Price = p
+		r.items[name] = Item{item.Name, item.Price, item.Tags}
+	}
+}
@@ -0,0 +1,96 @@
+package users
+
+import (
+	"errors"
+	"strings"
+)
+
+// User is a registered user.
+type User struct {
+	ID       int
+	Name     string
+	Email    *string // optional contact address
+	Age, Bio *int
+	Manager  *User
+}
+
+var store = map[int]*User{}
+
+// Find returns the user with the given ID, or nil.
+func Find(id int) (*User, error) {
+	if id < 0 {
+		return nil, errors.New("invalid id")
+	}
+	u, ok := store[id]
+	if !ok {
+		return nil, nil
+	}
+	return u, nil
+}
+
+// Lookup returns the user with the given ID, or nil.
+func Lookup(id int) *User {
+	u, _ := Find(id)
+	return u
+}
+
+// New creates a user.
+func New(name string, email string) *User {
+	age := 30
+	return &User{Name: name, Email: &email, Age: &age, Bio: nil}
+}
+
+// Domain returns the domain of the user's email address.
+func Domain(id int) string {
+	u, err := Find(id)
+	if err != nil || u == nil {
+		return ""
+	}
+	if u.Email != nil {
+		_, domain, _ := strings.Cut(*u.Email, "@")
+		return domain
+	}
+	return ""
+}
+
+// SetEmail changes the user's email address.
+func (u *User) SetEmail(email string) {
+	if u.Email == nil {
+		u.Email = &email
+		return
+	}
+	*u.Email = email
+}
+
+// ClearEmail removes the user's email address.
+func (u *User) ClearEmail() {
+	u.Email = nil
+}
+
+// Describe prints the user.
+func Describe(u *User) string {
+	age := "?"
+	if nil != u.Age {
+		age = string(rune('0' + *u.Age/10))
+	}
+	return u.Name + " " + age + " " + display(u.Email)
+}
+
+func display(s *string) string {
+	if s == nil {
+		return "-"
+	}
+	return *s
+}
+
+// Exists reports whether a user exists.
+func Exists(id int) bool {
+	return Lookup(id) != nil
+}
+
+// Reload refreshes u from the store.
+func Reload(u *User) (*User, error) {
+	var err error
+	u, err = Find(u.ID)
+	return u, err
+}
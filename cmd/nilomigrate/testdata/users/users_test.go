@@ -0,0 +1,15 @@
+package users_test
+
+import (
+	"testing"
+
+	"github.com/javiorfo/nilo/cmd/nilomigrate/testdata/users"
+)
+
+func TestNew(t *testing.T) {
+	u := users.New("ana", "ana@mail.com")
+	if u.Email == nil || *u.Email != "ana@mail.com" {
+		t.Fatal("wrong email")
+	}
+	u.Bio = u.Age
+}
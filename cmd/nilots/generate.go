@@ -0,0 +1,469 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const niloPath = "github.com/javiorfo/nilo"
+
+var identifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// generator collects the types to declare and writes their declarations in
+// the order they are found.
+type generator struct {
+	queue    []*types.TypeName
+	queued   map[*types.TypeName]bool
+	names    map[string]*types.TypeName
+	fset     *token.FileSet
+	docs     map[token.Position]string
+	found    map[string]bool
+	packages []string
+}
+
+func newGenerator(fset *token.FileSet) *generator {
+	return &generator{
+		fset:   fset,
+		queued: map[*types.TypeName]bool{},
+		names:  map[string]*types.TypeName{},
+		docs:   map[token.Position]string{},
+		found:  map[string]bool{},
+	}
+}
+
+// addPackage queues the exported types of pkg, or the named ones it
+// declares, and records the doc comments of the declarations in files,
+// which may be parsed apart from the files pkg was checked from.
+func (g *generator) addPackage(pkg *types.Package, files []*ast.File, names []string) error {
+	g.packages = append(g.packages, pkg.Path())
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch d := n.(type) {
+			case *ast.GenDecl:
+				if d.Tok == token.TYPE && len(d.Specs) == 1 && d.Doc != nil {
+					g.docs[g.fset.Position(d.Specs[0].(*ast.TypeSpec).Name.Pos())] = d.Doc.Text()
+				}
+			case *ast.TypeSpec:
+				if d.Doc != nil {
+					g.docs[g.fset.Position(d.Name.Pos())] = d.Doc.Text()
+				}
+			case *ast.Field:
+				doc := d.Doc
+				if doc == nil {
+					doc = d.Comment
+				}
+				for _, id := range d.Names {
+					if doc != nil {
+						g.docs[g.fset.Position(id.Pos())] = doc.Text()
+					}
+				}
+			}
+			return true
+		})
+	}
+
+	var objs []*types.TypeName
+	if len(names) > 0 {
+		for _, name := range names {
+			if obj, ok := pkg.Scope().Lookup(name).(*types.TypeName); ok {
+				g.found[name] = true
+				objs = append(objs, obj)
+			}
+		}
+	} else {
+		for _, name := range pkg.Scope().Names() {
+			if obj, ok := pkg.Scope().Lookup(name).(*types.TypeName); ok && obj.Exported() && !obj.IsAlias() {
+				objs = append(objs, obj)
+			}
+		}
+		slices.SortFunc(objs, func(a, b *types.TypeName) int { return int(a.Pos() - b.Pos()) })
+	}
+
+	for _, obj := range objs {
+		if named, ok := obj.Type().(*types.Named); ok && named.TypeParams().Len() > 0 {
+			if len(names) > 0 {
+				return fmt.Errorf("generic type %s cannot be generated", obj.Name())
+			}
+			continue
+		}
+		g.enqueue(obj)
+	}
+	return nil
+}
+
+// missing returns the names not declared by any of the added packages.
+func (g *generator) missing(names []string) []string {
+	var missing []string
+	for _, name := range names {
+		if !g.found[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+func (g *generator) enqueue(obj *types.TypeName) string {
+	name := obj.Name()
+	if other, ok := g.names[name]; ok && other != obj {
+		name = obj.Pkg().Name() + name
+	}
+	if !g.queued[obj] {
+		g.queued[obj] = true
+		g.names[name] = obj
+		g.queue = append(g.queue, obj)
+	}
+	return name
+}
+
+// generate writes the declarations of the queued types and of the types
+// they reference.
+func (g *generator) generate() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by nilots from %s. DO NOT EDIT.\n", strings.Join(g.packages, ", "))
+	for i := 0; i < len(g.queue); i++ {
+		obj := g.queue[i]
+		buf.WriteByte('\n')
+		g.doc(&buf, obj.Pos(), "")
+		name := g.enqueue(obj)
+		if st, ok := obj.Type().Underlying().(*types.Struct); ok && !isSpecial(obj.Type()) {
+			fmt.Fprintf(&buf, "export interface %s %s\n", name, g.object(st))
+			continue
+		}
+		fmt.Fprintf(&buf, "export type %s = %s;\n", name, g.alias(obj))
+	}
+	return buf.Bytes()
+}
+
+// alias returns the TypeScript type of the non-struct named type obj.
+func (g *generator) alias(obj *types.TypeName) string {
+	if isSpecial(obj.Type()) {
+		return g.tsType(obj.Type())
+	}
+	if _, ok := obj.Type().Underlying().(*types.Basic); ok {
+		if values := enumValues(obj); len(values) > 0 {
+			return strings.Join(values, " | ")
+		}
+	}
+	return g.tsType(obj.Type().Underlying())
+}
+
+// enumValues returns the literals of the constants of type obj declared in
+// its package.
+func enumValues(obj *types.TypeName) []string {
+	var consts []*types.Const
+	scope := obj.Pkg().Scope()
+	for _, name := range scope.Names() {
+		if c, ok := scope.Lookup(name).(*types.Const); ok && c.Exported() && types.Identical(c.Type(), obj.Type()) {
+			consts = append(consts, c)
+		}
+	}
+	slices.SortFunc(consts, func(a, b *types.Const) int { return int(a.Pos() - b.Pos()) })
+
+	var values []string
+	for _, c := range consts {
+		v := c.Val().ExactString()
+		if c.Val().Kind() == constant.String {
+			v = strconv.Quote(constant.StringVal(c.Val()))
+		}
+		if !slices.Contains(values, v) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// field is a property of a TypeScript object type.
+type field struct {
+	name     string
+	ts       string
+	optional bool
+	// depth is the number of embedded structs the field is promoted
+	// through.
+	depth  int
+	tagged bool
+	pos    token.Pos
+}
+
+// object returns the TypeScript object type of st.
+func (g *generator) object(st *types.Struct) string {
+	fields := dominant(g.fields(st, nil))
+	if len(fields) == 0 {
+		return "{}"
+	}
+	var sb strings.Builder
+	sb.WriteString("{\n")
+	for _, f := range fields {
+		g.doc(&sb, f.pos, "  ")
+		name := f.name
+		if !identifier.MatchString(name) {
+			name = strconv.Quote(name)
+		}
+		optional := ""
+		if f.optional {
+			optional = "?"
+		}
+		fmt.Fprintf(&sb, "  %s%s: %s;\n", name, optional, strings.ReplaceAll(f.ts, "\n", "\n  "))
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// fields returns the JSON properties of st following the rules of
+// `encoding/json`: tags rename or skip fields, unexported fields are left
+// out and untagged embedded structs are flattened. Fields sharing a name
+// are all kept; `dominant` chooses between them.
+func (g *generator) fields(st *types.Struct, seen []*types.Struct) []field {
+	if slices.Contains(seen, st) {
+		return nil
+	}
+	seen = append(seen, st)
+
+	var fields []field
+	for i := range st.NumFields() {
+		v := st.Field(i)
+		name, opts, found := strings.Cut(reflect.StructTag(st.Tag(i)).Get("json"), ",")
+		if name == "-" && !found {
+			continue
+		}
+
+		t := v.Type()
+		if p, ok := t.Underlying().(*types.Pointer); ok && v.Embedded() {
+			t = p.Elem()
+		}
+		if inner, ok := t.Underlying().(*types.Struct); ok && v.Embedded() && name == "" && !isSpecial(t) {
+			for _, f := range g.fields(inner, seen) {
+				f.depth++
+				fields = append(fields, f)
+			}
+			continue
+		}
+		if !v.Exported() {
+			continue
+		}
+
+		tagged := name != ""
+		if name == "" {
+			name = v.Name()
+		}
+		ts := g.tsType(v.Type())
+		if ts == "" {
+			continue
+		}
+		if hasOpt(opts, "string") {
+			ts = stringified(v.Type(), ts)
+		}
+		fields = append(fields, field{
+			name:     name,
+			ts:       ts,
+			optional: hasOpt(opts, "omitempty") || hasOpt(opts, "omitzero"),
+			tagged:   tagged,
+			pos:      v.Pos(),
+		})
+	}
+	return fields
+}
+
+// dominant keeps, for each name, the field `encoding/json` encodes: the
+// shallowest one, or the only tagged one among the shallowest. When several
+// remain, as with two embedded structs declaring the same name at the same
+// depth, the name is dropped.
+func dominant(fields []field) []field {
+	byName := map[string][]field{}
+	for _, f := range fields {
+		byName[f.name] = append(byName[f.name], f)
+	}
+	return slices.DeleteFunc(fields, func(f field) bool {
+		var shallowest []field
+		for _, other := range byName[f.name] {
+			switch {
+			case len(shallowest) == 0 || other.depth < shallowest[0].depth:
+				shallowest = []field{other}
+			case other.depth == shallowest[0].depth:
+				shallowest = append(shallowest, other)
+			}
+		}
+		if len(shallowest) > 1 {
+			shallowest = slices.DeleteFunc(shallowest, func(f field) bool { return !f.tagged })
+		}
+		return len(shallowest) != 1 || shallowest[0].pos != f.pos
+	})
+}
+
+// tsType returns the TypeScript type of the JSON encoding of t, or "" if
+// t cannot be encoded.
+func (g *generator) tsType(t types.Type) string {
+	if elem, ok := optionElem(t); ok {
+		return nullable(g.tsType(elem))
+	}
+
+	switch named := types.Unalias(t).(type) {
+	case *types.Named:
+		if ts, ok := special(named); ok {
+			return ts
+		}
+		if named.TypeArgs().Len() > 0 {
+			return g.tsType(named.Underlying())
+		}
+		switch named.Underlying().(type) {
+		case *types.Signature, *types.Chan:
+			return ""
+		}
+		return g.enqueue(named.Obj())
+	case *types.TypeParam:
+		return "unknown"
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsString != 0:
+			return "string"
+		case u.Info()&types.IsBoolean != 0:
+			return "boolean"
+		case u.Info()&(types.IsInteger|types.IsFloat) != 0:
+			return "number"
+		}
+		return ""
+	case *types.Pointer:
+		ts := g.tsType(u.Elem())
+		if ts == "" {
+			return ""
+		}
+		return nullable(ts)
+	case *types.Slice:
+		if b, ok := u.Elem().Underlying().(*types.Basic); ok && b.Kind() == types.Byte {
+			return "string"
+		}
+		return nullable(array(g.tsType(u.Elem())))
+	case *types.Array:
+		return array(g.tsType(u.Elem()))
+	case *types.Map:
+		return nullable("Record<string, " + g.tsType(u.Elem()) + ">")
+	case *types.Struct:
+		return g.object(u)
+	case *types.Interface:
+		return "unknown"
+	}
+	return ""
+}
+
+// optionElem returns T if t is `nilo.Option[T]`.
+func optionElem(t types.Type) (types.Type, bool) {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != niloPath || named.Obj().Name() != "Option" {
+		return nil, false
+	}
+	return named.TypeArgs().At(0), true
+}
+
+// special returns the TypeScript type of named types with their own JSON
+// encoding.
+func special(named *types.Named) (string, bool) {
+	obj := named.Obj()
+	if obj.Pkg() != nil {
+		switch obj.Pkg().Path() + "." + obj.Name() {
+		case "time.Time":
+			return "string", true
+		case "time.Duration":
+			return "number", true
+		case "encoding/json.RawMessage", "encoding/json.Number":
+			return "unknown", true
+		}
+	}
+	switch {
+	case implements(named, "MarshalJSON"):
+		return "unknown", true
+	case implements(named, "MarshalText"):
+		return "string", true
+	}
+	return "", false
+}
+
+func isSpecial(t types.Type) bool {
+	if _, ok := optionElem(t); ok {
+		return true
+	}
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return false
+	}
+	_, ok = special(named)
+	return ok
+}
+
+// implements reports whether t or *t has the method of the marshaler
+// interfaces of `encoding/json` and `encoding`.
+func implements(t types.Type, method string) bool {
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(t), false, nil, method)
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+	sig := fn.Type().(*types.Signature)
+	return sig.Params().Len() == 0 && sig.Results().Len() == 2
+}
+
+// stringified returns the type of a field tagged with the `string` option,
+// which quotes numbers and booleans.
+func stringified(t types.Type, ts string) string {
+	if p, ok := t.Underlying().(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	if b, ok := t.Underlying().(*types.Basic); ok && b.Info()&(types.IsNumeric|types.IsBoolean) != 0 {
+		return strings.Replace(ts, strings.TrimSuffix(ts, " | null"), "string", 1)
+	}
+	return ts
+}
+
+func (g *generator) doc(buf interface{ WriteString(string) (int, error) }, pos token.Pos, indent string) {
+	text := strings.TrimSpace(g.docs[g.fset.Position(pos)])
+	if text == "" {
+		return
+	}
+	text = strings.ReplaceAll(text, "*/", "*\\/")
+	lines := strings.Split(text, "\n")
+	if len(lines) == 1 {
+		buf.WriteString(indent + "/** " + lines[0] + " */\n")
+		return
+	}
+	buf.WriteString(indent + "/**\n")
+	for _, l := range lines {
+		buf.WriteString(strings.TrimRight(indent+" * "+l, " ") + "\n")
+	}
+	buf.WriteString(indent + " */\n")
+}
+
+func hasOpt(opts, name string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == name {
+			return true
+		}
+	}
+	return false
+}
+
+func nullable(ts string) string {
+	if ts == "" || ts == "unknown" || strings.HasSuffix(ts, " | null") {
+		return ts
+	}
+	return ts + " | null"
+}
+
+func array(ts string) string {
+	if ts == "" {
+		return ""
+	}
+	if strings.HasSuffix(ts, " | null") {
+		ts = "(" + ts + ")"
+	}
+	return ts + "[]"
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNullable(t *testing.T) {
+	assert.Equal(t, "string | null", nullable("string"))
+	assert.Equal(t, "string | null", nullable("string | null"))
+	assert.Equal(t, "unknown", nullable("unknown"))
+	assert.Equal(t, "", nullable(""))
+}
+
+func TestArray(t *testing.T) {
+	assert.Equal(t, "number[]", array("number"))
+	assert.Equal(t, "(number | null)[]", array("number | null"))
+	assert.Equal(t, "Record<string, number | null>[]", array("Record<string, number | null>"))
+	assert.Equal(t, "(Record<string, number> | null)[]", array("Record<string, number> | null"))
+	assert.Equal(t, "", array(""))
+}
+
+func TestHasOpt(t *testing.T) {
+	assert.True(t, hasOpt("omitempty,string", "string"))
+	assert.False(t, hasOpt("omitempty", "omitzero"))
+	assert.False(t, hasOpt("", "string"))
+}
+
+func TestDominant(t *testing.T) {
+	fields := []field{
+		{name: "id", pos: token.Pos(1)},
+		{name: "id", depth: 1, pos: token.Pos(2)},
+		{name: "source", depth: 1, tagged: true, pos: token.Pos(3)},
+		{name: "source", depth: 1, tagged: true, pos: token.Pos(4)},
+		{name: "Actor", depth: 1, pos: token.Pos(5)},
+		{name: "Actor", depth: 1, tagged: true, pos: token.Pos(6)},
+		{name: "Kind", depth: 2, pos: token.Pos(7)},
+		{name: "Kind", depth: 2, pos: token.Pos(8)},
+		{name: "span", depth: 2, pos: token.Pos(9)},
+	}
+
+	var kept []token.Pos
+	for _, f := range dominant(fields) {
+		kept = append(kept, f.pos)
+	}
+
+	assert.Equal(t, []token.Pos{1, 6, 9}, kept)
+}
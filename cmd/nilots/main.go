@@ -0,0 +1,161 @@
+// Command nilots generates TypeScript declarations mirroring the JSON
+// encoding of Go types.
+//
+// Every exported type of the packages, and the types they reference, is
+// written as a TypeScript interface or type alias:
+//
+//   - `nilo.Option[T]` and `*T` fields become `T | null`;
+//   - fields tagged `omitempty` or `omitzero` become optional (`?:`);
+//   - JSON tags rename and skip fields, and `,string` turns numbers and
+//     booleans into strings;
+//   - untagged embedded structs are flattened, as `encoding/json` does,
+//     dropping names declared twice at the same depth;
+//   - slices and maps may be `null`, byte slices, `time.Time` and
+//     `encoding.TextMarshaler` implementations are strings;
+//   - named string and number types with constants become unions of their
+//     values.
+//
+// Usage:
+//
+//	nilots [-o file.ts] [-types A,B] [dir]...
+//
+// Packages are loaded with `go/types`, importing their dependencies from
+// source; only the doc comments of the given packages are kept.
+//
+// Without -o, the declarations are printed on standard output.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	out := flag.String("o", "", "write the declarations to `file` instead of standard output")
+	only := flag.String("types", "", "comma separated `names` of the types to generate, with the types they reference")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: nilots [-o file.ts] [-types A,B] [dir]...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	dirs := flag.Args()
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	var names []string
+	if *only != "" {
+		for _, name := range strings.Split(*only, ",") {
+			names = append(names, strings.TrimSpace(name))
+		}
+	}
+
+	src, err := generate(dirs, names)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "nilots:", err)
+		os.Exit(1)
+	}
+	if *out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "nilots:", err)
+		os.Exit(1)
+	}
+}
+
+// generate returns the TypeScript declarations of the packages in dirs,
+// restricted to the named types when names is not empty.
+func generate(dirs, names []string) ([]byte, error) {
+	fset := token.NewFileSet()
+	// Loading the packages with the importer shares them with the packages
+	// importing each other, so every type is declared once.
+	imp := importer.ForCompiler(fset, "source", nil).(types.ImporterFrom)
+	g := newGenerator(fset)
+
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		files, err := parseDir(fset, abs)
+		if err != nil {
+			return nil, err
+		}
+		pkg, err := imp.ImportFrom(importPath(dir), abs, 0)
+		if err != nil {
+			return nil, err
+		}
+		if err := g.addPackage(pkg, files, names); err != nil {
+			return nil, err
+		}
+	}
+	if missing := g.missing(names); len(missing) > 0 {
+		return nil, fmt.Errorf("types not found: %s", strings.Join(missing, ", "))
+	}
+	return g.generate(), nil
+}
+
+// parseDir parses the non-test Go files of dir matching the build
+// constraints, with their comments.
+func parseDir(fset *token.FileSet, dir string) ([]*ast.File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []*ast.File
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		if ok, err := build.Default.MatchFile(dir, name); err != nil || !ok {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+	return files, nil
+}
+
+// importPath returns the import path of the package in dir from the
+// module path of its go.mod file, or dir itself outside a module.
+func importPath(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+	for root := abs; ; {
+		data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+		if err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if v, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+					rel, _ := filepath.Rel(root, abs)
+					return filepath.ToSlash(filepath.Join(strings.Trim(strings.TrimSpace(v), `"`), rel))
+				}
+			}
+			return dir
+		}
+		parent := filepath.Dir(root)
+		if parent == root {
+			return dir
+		}
+		root = parent
+	}
+}
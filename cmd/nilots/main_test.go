@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// golden compares got with the content of the golden file, or rewrites it
+// with -update.
+func golden(t *testing.T, path string, got []byte) {
+	t.Helper()
+	if *update {
+		assert.NoError(t, os.WriteFile(path, got, 0o644))
+		return
+	}
+	want, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestGenerate(t *testing.T) {
+	t.Run("every exported type", func(t *testing.T) {
+		src, err := generate([]string{filepath.Join("testdata", "api")}, nil)
+		assert.NoError(t, err)
+		golden(t, filepath.Join("testdata", "api.ts.golden"), src)
+	})
+
+	t.Run("named types across packages", func(t *testing.T) {
+		dirs := []string{filepath.Join("testdata", "billing"), filepath.Join("testdata", "api")}
+		src, err := generate(dirs, []string{"Invoice"})
+		assert.NoError(t, err)
+		golden(t, filepath.Join("testdata", "billing.ts.golden"), src)
+	})
+
+	t.Run("unknown types", func(t *testing.T) {
+		_, err := generate([]string{filepath.Join("testdata", "billing")}, []string{"Invoice", "Receipt"})
+		assert.EqualError(t, err, "types not found: Receipt")
+	})
+
+	t.Run("generic types", func(t *testing.T) {
+		_, err := generate([]string{filepath.Join("testdata", "api")}, []string{"Page"})
+		assert.EqualError(t, err, "generic type Page cannot be generated")
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		_, err := generate([]string{filepath.Join("testdata", "none")}, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestImportPath(t *testing.T) {
+	assert.Equal(t, "github.com/javiorfo/nilo/cmd/nilots/testdata/api", importPath(filepath.Join("testdata", "api")))
+	assert.Equal(t, "/", importPath("/"))
+}
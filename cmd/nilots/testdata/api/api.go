@@ -0,0 +1,118 @@
+// Package api exercises the mapping rules of nilots.
+package api
+
+import (
+	"encoding/json"
+	"net/netip"
+	"time"
+
+	"github.com/javiorfo/nilo"
+)
+
+// Role is the access level of a User.
+type Role string
+
+const (
+	Admin  Role = "admin"
+	Member Role = "member"
+	Guest  Role = "guest"
+)
+
+// Status is a numeric enumeration.
+type Status int
+
+const (
+	Active Status = iota + 1
+	Suspended
+)
+
+// ID is a named type without constants.
+type ID int64
+
+// Base holds the fields shared by every resource.
+type Base struct {
+	ID        ID        `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	// UpdatedAt is shadowed by the embedding struct.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// User is an account.
+//
+// It is returned by the users endpoints.
+type User struct {
+	Base
+	UpdatedAt nilo.Option[time.Time] `json:"updated_at"`
+	// Name is the display name.
+	Name     string               `json:"name"`
+	Email    nilo.Option[string]  `json:"email"`
+	Nickname nilo.Option[string]  `json:"nickname,omitzero"`
+	Age      *int                 `json:"age,omitempty"`
+	Role     Role                 `json:"role"`
+	Status   Status               `json:"status"`
+	Tags     []string             `json:"tags"`
+	Scores   [3]float64           `json:"scores"`
+	Labels   map[string]string    `json:"labels,omitempty"`
+	Avatar   []byte               `json:"avatar"`
+	Balance  int64                `json:"balance,string"`
+	Verified nilo.Option[bool]    `json:"verified,string"`
+	Address  nilo.Option[Address] `json:"address"`
+	Friends  []nilo.Option[ID]    `json:"friends"`
+	Extra    json.RawMessage      `json:"extra"`
+	IP       netip.Addr           `json:"ip"`
+	Timeout  time.Duration        `json:"timeout"`
+	Meta     any                  `json:"meta"`
+	Settings struct {
+		Theme    nilo.Option[string] `json:"theme"`
+		Language string              `json:"language"`
+	} `json:"settings"`
+	Page     Page[Address] `json:"page"`
+	Password string        `json:"-"`
+	Dash     string        `json:"-,"`
+	Kebab    string        `json:"kebab-case"`
+	Untagged bool
+	OnChange func() `json:"on_change"`
+	internal string
+}
+
+// Address is referenced by User and generated on demand.
+type Address struct {
+	Street string              `json:"street"`
+	City   nilo.Option[string] `json:"city,omitempty"`
+}
+
+// Page is generic, so it is only inlined where instantiated.
+type Page[T any] struct {
+	Items []T                 `json:"items"`
+	Next  nilo.Option[string] `json:"next"`
+}
+
+// Tree refers to itself.
+type Tree struct {
+	Value    int     `json:"value"`
+	Children []*Tree `json:"children"`
+}
+
+// Audit and Trace both declare source at the same depth.
+type Audit struct {
+	Source string `json:"source"`
+	Actor  string `json:"actor"`
+}
+
+// Trace is embedded next to Audit by Event.
+type Trace struct {
+	Source string `json:"source"`
+	Span   string `json:"span"`
+}
+
+// Event has no source, as the embedded ones cancel each other out in
+// encoding/json.
+type Event struct {
+	Audit
+	Trace
+	Kind string `json:"kind"`
+}
+
+type hidden struct {
+	Secret string `json:"secret"`
+}
@@ -0,0 +1,28 @@
+// Package billing references the types of package api.
+package billing
+
+import (
+	"github.com/javiorfo/nilo"
+	"github.com/javiorfo/nilo/cmd/nilots/testdata/api"
+)
+
+// Invoice is billed to a User.
+type Invoice struct {
+	Number   string               `json:"number"`
+	Customer api.User             `json:"customer"`
+	Billing  nilo.Option[Address] `json:"billing,omitzero"`
+	Lines    []Line               `json:"lines"`
+}
+
+// Address has the same name as api.Address.
+type Address struct {
+	Line1 string `json:"line1"`
+}
+
+// Line is an item of an Invoice.
+type Line struct {
+	Amount nilo.Option[float64] `json:"amount"`
+}
+
+// Unused is not generated when only Invoice is requested.
+type Unused struct{}
@@ -0,0 +1,406 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"go/version"
+	"maps"
+)
+
+const niloPath = "github.com/javiorfo/nilo"
+
+// unchecked are the `Option` methods that misbehave on `Nil`.
+var unchecked = map[string]string{
+	"AsValue": "panics",
+	"AsPtr":   "returns nil",
+	"OrPanic": "panics",
+}
+
+type diagnostic struct {
+	pos     token.Pos
+	message string
+}
+
+// checker runs the checks over the files of one package.
+type checker struct {
+	info  *types.Info
+	diags []diagnostic
+}
+
+// facts holds the receivers, printed as Go expressions, known to be
+// `Value` at a point of a function.
+type facts map[string]bool
+
+func (f facts) with(other facts) facts {
+	merged := maps.Clone(f)
+	maps.Copy(merged, other)
+	return merged
+}
+
+func check(files []*ast.File, info *types.Info, goVersion string) []diagnostic {
+	c := &checker{info: info}
+	for _, f := range files {
+		fileVersion := goVersion
+		if f.GoVersion != "" {
+			fileVersion = f.GoVersion
+		}
+		if fileVersion == "" || version.Compare(fileVersion, "go1.22") < 0 {
+			c.loopVars(f)
+		}
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Body != nil {
+				c.stmts(fn.Body.List, facts{})
+			}
+		}
+	}
+	return c.diags
+}
+
+func (c *checker) report(pos token.Pos, format string) {
+	c.diags = append(c.diags, diagnostic{pos, format})
+}
+
+// stmts checks a list of statements, learning from early exits such as
+// `if o.IsNil() { return }` that an `Option` is `Value` afterwards.
+func (c *checker) stmts(list []ast.Stmt, known facts) {
+	known = maps.Clone(known)
+	for _, stmt := range list {
+		c.stmt(stmt, known)
+		c.forgetAssigned(known, stmt)
+		if s, ok := stmt.(*ast.IfStmt); ok && s.Init == nil {
+			switch {
+			case terminates(s.Body) && (s.Else == nil || terminates(s.Else)):
+				maps.Copy(known, c.negative(s.Cond))
+			case s.Else != nil && terminates(s.Else):
+				maps.Copy(known, c.positive(s.Cond))
+			}
+		}
+	}
+}
+
+func (c *checker) stmt(stmt ast.Stmt, known facts) {
+	switch s := stmt.(type) {
+	case *ast.BlockStmt:
+		c.stmts(s.List, known)
+	case *ast.IfStmt:
+		if s.Init != nil {
+			c.stmt(s.Init, known)
+		}
+		c.expr(s.Cond, known)
+		c.stmts(s.Body.List, known.with(c.positive(s.Cond)))
+		if s.Else != nil {
+			c.stmt(s.Else, known.with(c.negative(s.Cond)))
+		}
+	case *ast.ForStmt:
+		if s.Init != nil {
+			c.stmt(s.Init, known)
+		}
+		// Facts learned before the loop hold in the first iteration only
+		// for receivers the loop does not change.
+		c.forgetAssigned(known, s.Body)
+		c.forgetAssigned(known, s.Post)
+		body := known
+		if s.Cond != nil {
+			c.expr(s.Cond, known)
+			body = known.with(c.positive(s.Cond))
+		}
+		if s.Post != nil {
+			c.stmt(s.Post, body)
+		}
+		c.stmts(s.Body.List, body)
+	case *ast.RangeStmt:
+		c.expr(s.X, known)
+		c.forgetAssigned(known, s)
+		c.stmts(s.Body.List, known)
+	case *ast.SwitchStmt:
+		if s.Init != nil {
+			c.stmt(s.Init, known)
+		}
+		if s.Tag != nil {
+			c.expr(s.Tag, known)
+		}
+		// In a switch without tag, a case is only reached when the
+		// previous ones are false.
+		reached := known
+		for _, clause := range s.Body.List {
+			cc := clause.(*ast.CaseClause)
+			body := reached
+			for _, e := range cc.List {
+				c.expr(e, reached)
+			}
+			if s.Tag == nil && len(cc.List) == 1 {
+				body = reached.with(c.positive(cc.List[0]))
+				reached = reached.with(c.negative(cc.List[0]))
+			}
+			c.stmts(cc.Body, body)
+		}
+	case *ast.TypeSwitchStmt:
+		if s.Init != nil {
+			c.stmt(s.Init, known)
+		}
+		c.stmt(s.Assign, known)
+		for _, clause := range s.Body.List {
+			c.stmts(clause.(*ast.CaseClause).Body, known)
+		}
+	case *ast.SelectStmt:
+		for _, clause := range s.Body.List {
+			cc := clause.(*ast.CommClause)
+			if cc.Comm != nil {
+				c.stmt(cc.Comm, known)
+			}
+			c.stmts(cc.Body, known)
+		}
+	case *ast.LabeledStmt:
+		c.stmt(s.Stmt, known)
+	case *ast.AssignStmt:
+		c.expr(s, known)
+		for _, lhs := range s.Lhs {
+			forget(known, lhs)
+		}
+	case nil:
+	default:
+		c.expr(s, known)
+	}
+}
+
+// expr checks the expressions below node, following the short-circuit of
+// `&&` and `||` and the function literals.
+func (c *checker) expr(node ast.Node, known facts) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch e := n.(type) {
+		case *ast.BinaryExpr:
+			switch e.Op {
+			case token.LAND:
+				c.expr(e.X, known)
+				c.expr(e.Y, known.with(c.positive(e.X)))
+				return false
+			case token.LOR:
+				c.expr(e.X, known)
+				c.expr(e.Y, known.with(c.negative(e.X)))
+				return false
+			case token.EQL, token.NEQ:
+				if c.isOptionValue(e.X) || c.isOptionValue(e.Y) {
+					c.report(e.OpPos, "nilo.Option compared with "+e.Op.String()+": this compares internal pointers, use IsNil, IsValue or compare the contained values")
+				}
+			}
+		case *ast.FuncLit:
+			// A function literal may run after its receivers change, so
+			// it starts without facts.
+			c.stmts(e.Body.List, facts{})
+			return false
+		case *ast.CallExpr:
+			recv, method, ok := c.optionCall(e)
+			if !ok {
+				return true
+			}
+			if effect, risky := unchecked[method]; risky && !known[recv] {
+				c.report(e.Pos(), method+" "+effect+" on Nil and "+recv+" is not checked with IsValue or IsNil first")
+			}
+			if method == "Take" || method == "TakeIf" {
+				delete(known, recv)
+			}
+		}
+		return true
+	})
+}
+
+// positive returns the receivers known to be `Value` when cond is true.
+func (c *checker) positive(cond ast.Expr) facts {
+	switch e := ast.Unparen(cond).(type) {
+	case *ast.UnaryExpr:
+		if e.Op == token.NOT {
+			return c.negative(e.X)
+		}
+	case *ast.BinaryExpr:
+		if e.Op == token.LAND {
+			return c.positive(e.X).with(c.positive(e.Y))
+		}
+	case *ast.CallExpr:
+		if recv, method, ok := c.optionCall(e); ok && (method == "IsValue" || method == "IsValueAnd") {
+			return facts{recv: true}
+		}
+	}
+	return facts{}
+}
+
+// negative returns the receivers known to be `Value` when cond is false.
+func (c *checker) negative(cond ast.Expr) facts {
+	switch e := ast.Unparen(cond).(type) {
+	case *ast.UnaryExpr:
+		if e.Op == token.NOT {
+			return c.positive(e.X)
+		}
+	case *ast.BinaryExpr:
+		if e.Op == token.LOR {
+			return c.negative(e.X).with(c.negative(e.Y))
+		}
+	case *ast.CallExpr:
+		if recv, method, ok := c.optionCall(e); ok && (method == "IsNil" || method == "IsNilOr") {
+			return facts{recv: true}
+		}
+	}
+	return facts{}
+}
+
+// optionCall returns the receiver and the name of a method call on a
+// `nilo.Option`.
+func (c *checker) optionCall(call *ast.CallExpr) (string, string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", "", false
+	}
+	s, ok := c.info.Selections[sel]
+	if !ok || s.Kind() != types.MethodVal || !isOption(s.Recv()) {
+		return "", "", false
+	}
+	return types.ExprString(sel.X), sel.Sel.Name, true
+}
+
+// isOptionValue reports whether e is a `nilo.Option`, not a pointer to one.
+func (c *checker) isOptionValue(e ast.Expr) bool {
+	t := c.info.TypeOf(e)
+	if t == nil {
+		return false
+	}
+	_, ptr := t.Underlying().(*types.Pointer)
+	return !ptr && isOption(t)
+}
+
+// loopVars reports `nilo.Ptr(&v)` calls where v is a loop variable.
+func (c *checker) loopVars(f *ast.File) {
+	vars := map[types.Object]bool{}
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch s := n.(type) {
+		case *ast.RangeStmt:
+			if s.Tok == token.DEFINE {
+				for _, e := range []ast.Expr{s.Key, s.Value} {
+					if id, ok := e.(*ast.Ident); ok && c.info.Defs[id] != nil {
+						vars[c.info.Defs[id]] = true
+					}
+				}
+			}
+		case *ast.ForStmt:
+			if init, ok := s.Init.(*ast.AssignStmt); ok && init.Tok == token.DEFINE {
+				for _, e := range init.Lhs {
+					if id, ok := e.(*ast.Ident); ok && c.info.Defs[id] != nil {
+						vars[c.info.Defs[id]] = true
+					}
+				}
+			}
+		case *ast.CallExpr:
+			if !c.isPtrFunc(s.Fun) || len(s.Args) != 1 {
+				return true
+			}
+			addr, ok := ast.Unparen(s.Args[0]).(*ast.UnaryExpr)
+			if !ok || addr.Op != token.AND {
+				return true
+			}
+			if id, ok := ast.Unparen(addr.X).(*ast.Ident); ok && vars[c.info.Uses[id]] {
+				c.report(s.Pos(), "nilo.Ptr of loop variable "+id.Name+": every iteration shares the variable, so the Option changes with it")
+			}
+		}
+		return true
+	})
+}
+
+func (c *checker) isPtrFunc(fun ast.Expr) bool {
+	switch f := ast.Unparen(fun).(type) {
+	case *ast.IndexExpr:
+		fun = f.X
+	case *ast.IndexListExpr:
+		fun = f.X
+	}
+	var id *ast.Ident
+	switch f := ast.Unparen(fun).(type) {
+	case *ast.Ident:
+		id = f
+	case *ast.SelectorExpr:
+		id = f.Sel
+	default:
+		return false
+	}
+	fn, ok := c.info.Uses[id].(*types.Func)
+	return ok && fn.Name() == "Ptr" && fn.Pkg() != nil && fn.Pkg().Path() == niloPath
+}
+
+// forget drops the facts about the assigned expression and the expressions
+// below it.
+func forget(known facts, lhs ast.Expr) {
+	name := types.ExprString(lhs)
+	for recv := range known {
+		if recv == name || len(recv) > len(name) && recv[:len(name)+1] == name+"." {
+			delete(known, recv)
+		}
+	}
+}
+
+// forgetAssigned drops the facts about the receivers assigned, incremented
+// or taken from anywhere below node, function literals included.
+func (c *checker) forgetAssigned(known facts, node ast.Node) {
+	if node == nil || len(known) == 0 {
+		return
+	}
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch s := n.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range s.Lhs {
+				forget(known, lhs)
+			}
+		case *ast.IncDecStmt:
+			forget(known, s.X)
+		case *ast.RangeStmt:
+			for _, e := range []ast.Expr{s.Key, s.Value} {
+				if e != nil {
+					forget(known, e)
+				}
+			}
+		case *ast.CallExpr:
+			if recv, method, ok := c.optionCall(s); ok && (method == "Take" || method == "TakeIf") {
+				delete(known, recv)
+			}
+		}
+		return true
+	})
+}
+
+// terminates reports whether stmt never falls through to the next
+// statement.
+func terminates(stmt ast.Stmt) bool {
+	switch s := stmt.(type) {
+	case *ast.BlockStmt:
+		return len(s.List) > 0 && terminates(s.List[len(s.List)-1])
+	case *ast.ReturnStmt, *ast.BranchStmt:
+		return true
+	case *ast.IfStmt:
+		return s.Else != nil && terminates(s.Body) && terminates(s.Else)
+	case *ast.ExprStmt:
+		call, ok := s.X.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		switch f := call.Fun.(type) {
+		case *ast.Ident:
+			return f.Name == "panic"
+		case *ast.SelectorExpr:
+			switch f.Sel.Name {
+			case "Exit", "Fatal", "Fatalf", "Fatalln", "Panic", "Panicf", "Panicln", "FailNow", "Skip", "Skipf", "SkipNow":
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isOption reports whether t is a `nilo.Option` or a pointer to one.
+func isOption(t types.Type) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Name() == "Option" && obj.Pkg() != nil && obj.Pkg().Path() == niloPath
+}
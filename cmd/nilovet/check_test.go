@@ -0,0 +1,74 @@
+package main
+
+import (
+	"go/importer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var wantRe = regexp.MustCompile(`"([^"]+)"`)
+
+// wants returns the expected messages of the `// want "message"...`
+// comments of the Go files in dir, by file and line. A diagnostic matches
+// when its message contains the expected one.
+func wants(t *testing.T, dir string) map[string][]string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	assert.NoError(t, err)
+
+	result := map[string][]string{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		assert.NoError(t, err)
+		for i, line := range strings.Split(string(data), "\n") {
+			_, want, ok := strings.Cut(line, "// want ")
+			if !ok {
+				continue
+			}
+			key := filepath.Base(file) + ":" + strconv.Itoa(i+1)
+			for _, m := range wantRe.FindAllStringSubmatch(want, -1) {
+				result[key] = append(result[key], m[1])
+			}
+		}
+	}
+	return result
+}
+
+func TestCheck(t *testing.T) {
+	dir := filepath.Join("testdata", "src", "checks")
+	fset := token.NewFileSet()
+
+	diags, err := checkDir(fset, importer.ForCompiler(fset, "source", nil), dir, true)
+	assert.NoError(t, err)
+
+	expected := wants(t, dir)
+	for _, d := range diags {
+		pos := fset.Position(d.pos)
+		key := filepath.Base(pos.Filename) + ":" + strconv.Itoa(pos.Line)
+		patterns := expected[key]
+		matched := -1
+		for i, want := range patterns {
+			if strings.Contains(d.message, want) {
+				matched = i
+				break
+			}
+		}
+		if matched < 0 {
+			t.Errorf("%s: unexpected diagnostic: %s", key, d.message)
+			continue
+		}
+		expected[key] = append(patterns[:matched], patterns[matched+1:]...)
+	}
+	for key, patterns := range expected {
+		for _, want := range patterns {
+			t.Errorf("%s: missing diagnostic containing %q", key, want)
+		}
+	}
+}
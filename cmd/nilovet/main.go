@@ -0,0 +1,200 @@
+// Command nilovet reports risky uses of `nilo.Option`:
+//
+//   - calls to `AsValue`, `AsPtr` and `OrPanic` that are not guarded by an
+//     `IsValue` or `IsNil` check on the same `Option`;
+//   - `Option`s compared with `==` or `!=`, which compares their internal
+//     pointers instead of their values;
+//   - `nilo.Ptr` of the address of a loop variable, where the variable is
+//     shared by every iteration.
+//
+// The loop variable check only runs on files whose Go version, from the
+// `go` line of go.mod or a `//go:build` constraint, is below go1.22 or
+// unknown. Since go1.22 every iteration has its own variable, so the check
+// never fires in modules declaring go1.22 or later, this one included.
+//
+// Usage:
+//
+//	nilovet [-tests=false] [packages]
+//
+// Packages are directories, and a trailing "/..." also checks every
+// directory below. Without arguments, the current directory is checked.
+// Diagnostics are printed as "file:line:column: message" and the exit
+// status is 1 when any is found.
+//
+// nilovet is built only on the standard library: packages are parsed with
+// `go/parser` and type checked with `go/types`, importing dependencies from
+// source.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func main() {
+	tests := flag.Bool("tests", true, "also check test files")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: nilovet [-tests=false] [packages]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	patterns := flag.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	dirs, err := expand(patterns)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "nilovet:", err)
+		os.Exit(2)
+	}
+
+	fset := token.NewFileSet()
+	imp := importer.ForCompiler(fset, "source", nil)
+	var diags []diagnostic
+	for _, dir := range dirs {
+		ds, err := checkDir(fset, imp, dir, *tests)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "nilovet:", err)
+			os.Exit(2)
+		}
+		diags = append(diags, ds...)
+	}
+
+	for _, d := range diags {
+		fmt.Printf("%s: %s\n", fset.Position(d.pos), d.message)
+	}
+	if len(diags) > 0 {
+		os.Exit(1)
+	}
+}
+
+// expand turns the command line patterns into directories.
+func expand(patterns []string) ([]string, error) {
+	var dirs []string
+	for _, pattern := range patterns {
+		root, recursive := strings.CutSuffix(pattern, "/...")
+		if !recursive {
+			dirs = append(dirs, pattern)
+			continue
+		}
+		if root == "" {
+			root = "."
+		}
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return err
+			}
+			name := d.Name()
+			if path != root && (name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			dirs = append(dirs, path)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dirs, nil
+}
+
+// checkDir parses the Go files of dir that match the build constraints,
+// type checks each package they form and runs the checks on it.
+func checkDir(fset *token.FileSet, imp types.Importer, dir string, tests bool) ([]diagnostic, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pkgs := map[string][]*ast.File{}
+	var names []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || (!tests && strings.HasSuffix(name, "_test.go")) {
+			continue
+		}
+		if ok, err := build.Default.MatchFile(dir, name); err != nil || !ok {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		pkg := f.Name.Name
+		if _, ok := pkgs[pkg]; !ok {
+			names = append(names, pkg)
+		}
+		pkgs[pkg] = append(pkgs[pkg], f)
+	}
+
+	path, goVersion := module(dir)
+	var diags []diagnostic
+	for _, name := range names {
+		conf := types.Config{
+			Importer: imp,
+			Error: func(err error) {
+				fmt.Fprintln(os.Stderr, "nilovet:", err)
+			},
+		}
+		info := &types.Info{
+			Types:      map[ast.Expr]types.TypeAndValue{},
+			Defs:       map[*ast.Ident]types.Object{},
+			Uses:       map[*ast.Ident]types.Object{},
+			Selections: map[*ast.SelectorExpr]*types.Selection{},
+		}
+		pkgPath := path
+		if strings.HasSuffix(name, "_test") {
+			pkgPath += "_test"
+		}
+		_, _ = conf.Check(pkgPath, fset, pkgs[name], info)
+		diags = append(diags, check(pkgs[name], info, goVersion)...)
+	}
+
+	sort.Slice(diags, func(i, j int) bool { return diags[i].pos < diags[j].pos })
+	return diags, nil
+}
+
+// module returns the import path of the package in dir and the go
+// directive of its go.mod file, such as "go1.21". Outside a module, the
+// import path is the directory and the version is empty.
+func module(dir string) (string, string) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return dir, ""
+	}
+	for root := abs; ; {
+		data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+		if err == nil {
+			var modPath, goVersion string
+			for _, line := range strings.Split(string(data), "\n") {
+				line = strings.TrimSpace(line)
+				if v, ok := strings.CutPrefix(line, "module "); ok {
+					modPath = strings.Trim(strings.TrimSpace(v), `"`)
+				}
+				if v, ok := strings.CutPrefix(line, "go "); ok {
+					goVersion = "go" + strings.TrimSpace(v)
+				}
+			}
+			rel, _ := filepath.Rel(root, abs)
+			return filepath.ToSlash(filepath.Join(modPath, rel)), goVersion
+		}
+		parent := filepath.Dir(root)
+		if parent == root {
+			return dir, ""
+		}
+		root = parent
+	}
+}
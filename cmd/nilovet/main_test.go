@@ -0,0 +1,23 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpand(t *testing.T) {
+	dirs, err := expand([]string{"testdata/...", "."})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"testdata", filepath.Join("testdata", "src"), filepath.Join("testdata", "src", "checks"), "."}, dirs)
+}
+
+func TestModule(t *testing.T) {
+	path, goVersion := module(filepath.Join("testdata", "src", "checks"))
+
+	assert.Equal(t, "github.com/javiorfo/nilo/cmd/nilovet/testdata/src/checks", path)
+	assert.True(t, strings.HasPrefix(goVersion, "go1."))
+}
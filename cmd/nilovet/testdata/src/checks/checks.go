@@ -0,0 +1,194 @@
+package checks
+
+import (
+	"errors"
+	"log"
+
+	"github.com/javiorfo/nilo"
+)
+
+type user struct {
+	Email nilo.Option[string]
+}
+
+func unguarded(o nilo.Option[int]) int {
+	return o.AsValue() // want "AsValue panics on Nil and o is not checked"
+}
+
+func unguardedPtr(o nilo.Option[int]) *int {
+	return o.AsPtr() // want "AsPtr returns nil on Nil and o is not checked"
+}
+
+func unguardedPanic(o nilo.Option[int]) int {
+	return o.OrPanic("missing") // want "OrPanic panics on Nil"
+}
+
+func guardedIf(o nilo.Option[int]) int {
+	if o.IsValue() {
+		return o.AsValue()
+	}
+	return 0
+}
+
+func guardedElse(o nilo.Option[int]) int {
+	if o.IsNil() {
+		return 0
+	} else {
+		return o.AsValue()
+	}
+}
+
+func guardedEarlyReturn(u user) string {
+	if u.Email.IsNil() {
+		return ""
+	}
+	return u.Email.AsValue()
+}
+
+func guardedNegation(o nilo.Option[int]) (int, error) {
+	if !o.IsValue() {
+		return 0, errors.New("missing")
+	}
+	return o.AsValue(), nil
+}
+
+func guardedFatal(o nilo.Option[int]) int {
+	if o.IsNil() {
+		log.Fatal("missing")
+	}
+	return o.AsValue()
+}
+
+func guardedConditions(a, b nilo.Option[int]) bool {
+	if a.IsValue() && b.IsValue() && a.AsValue() > b.AsValue() {
+		return true
+	}
+	return a.IsNil() || a.AsValue() > 0
+}
+
+func guardedLoop(opts []nilo.Option[int]) (sum int) {
+	for _, o := range opts {
+		if o.IsNil() {
+			continue
+		}
+		sum += o.AsValue()
+	}
+	return sum
+}
+
+func guardedSwitch(o nilo.Option[int]) int {
+	switch {
+	case o.IsNil():
+		return 0
+	case o.AsValue() > 10:
+		return 10
+	}
+	return 1
+}
+
+func guardedClosure(o nilo.Option[int]) func() int {
+	return func() int {
+		if o.IsNil() {
+			return 0
+		}
+		return o.AsValue()
+	}
+}
+
+func closureAfterCheck(o nilo.Option[int], next nilo.Option[int]) func() int {
+	if o.IsNil() {
+		return nil
+	}
+	f := func() int { return o.AsValue() } // want "AsValue panics on Nil and o is not checked"
+	o = next
+	return f
+}
+
+func wrongReceiver(a, b nilo.Option[int]) int {
+	if a.IsValue() {
+		return b.AsValue() // want "AsValue panics on Nil and b is not checked"
+	}
+	return 0
+}
+
+func reassigned(o nilo.Option[int], next nilo.Option[int]) int {
+	if o.IsNil() {
+		return 0
+	}
+	o = next
+	return o.AsValue() // want "AsValue panics on Nil and o is not checked"
+}
+
+func taken(o *nilo.Option[int]) int {
+	if o.IsNil() {
+		return 0
+	}
+	o.Take()
+	return o.AsValue() // want "AsValue panics on Nil and o is not checked"
+}
+
+func reassignedInIf(o nilo.Option[int], next nilo.Option[int], reset bool) int {
+	if o.IsNil() {
+		return 0
+	}
+	if reset {
+		o = next
+	}
+	return o.AsValue() // want "AsValue panics on Nil and o is not checked"
+}
+
+func reassignedInLoop(o nilo.Option[int], opts []nilo.Option[int]) (sum int) {
+	if o.IsNil() {
+		return 0
+	}
+	for _, next := range opts {
+		sum += o.AsValue() // want "AsValue panics on Nil and o is not checked"
+		o = next
+	}
+	for i := 0; i < len(opts); i++ {
+		sum += o.AsValue() // want "AsValue panics on Nil and o is not checked"
+		o.Take()
+	}
+	return sum + o.AsValue() // want "AsValue panics on Nil and o is not checked"
+}
+
+func rangedInto(o nilo.Option[int], opts []nilo.Option[int]) int {
+	if o.IsNil() {
+		return 0
+	}
+	for _, o = range opts {
+	}
+	return o.AsValue() // want "AsValue panics on Nil and o is not checked"
+}
+
+func unchangedInLoop(o nilo.Option[int], opts []nilo.Option[int]) (sum int) {
+	if o.IsNil() {
+		return 0
+	}
+	for _, next := range opts {
+		sum += o.AsValue() + next.OrDefault()
+	}
+	return sum + o.AsValue()
+}
+
+func outsideIf(o nilo.Option[int]) int {
+	if o.IsValue() {
+		println("value")
+	}
+	return o.AsValue() // want "AsValue panics on Nil and o is not checked"
+}
+
+func compared(a, b nilo.Option[int], p *nilo.Option[int]) bool {
+	if p == nil {
+		return false
+	}
+	return a == b || a != nilo.Nil[int]() // want "nilo.Option compared with ==" "nilo.Option compared with !="
+}
+
+func loopPointers(values []int) []nilo.Option[int] {
+	var result []nilo.Option[int]
+	for _, v := range values {
+		result = append(result, nilo.Ptr(&v))
+	}
+	return result
+}
@@ -0,0 +1,19 @@
+//go:build go1.21
+
+package checks
+
+import "github.com/javiorfo/nilo"
+
+func legacyLoopPointers(values []int) []nilo.Option[int] {
+	var result []nilo.Option[int]
+	for _, v := range values {
+		result = append(result, nilo.Ptr(&v)) // want "nilo.Ptr of loop variable v"
+	}
+	for i := 0; i < 3; i++ {
+		result = append(result, nilo.Ptr[int](&i)) // want "nilo.Ptr of loop variable i"
+	}
+	for i := range values {
+		result = append(result, nilo.Ptr(&values[i]))
+	}
+	return result
+}
@@ -0,0 +1,221 @@
+// Package config resolves typed configuration keys from layered sources,
+// such as flags, environment variables, a config file and defaults, using
+// `nilo.Option` precedence: the first source holding a value wins.
+//
+//	r := config.New(config.Flags(flag.CommandLine), config.Env("APP"), config.Map("file", fileValues))
+//	port := config.Define[int](r, "port").Default(8080)
+//	token := config.Define[string](r, "token").Required().Secret()
+//
+//	if err := r.Validate(); err != nil {
+//		log.Fatal(err)
+//	}
+//	r.Print(os.Stdout) // port = 9090 (env)
+//
+// Raw values are converted with `encoding.TextUnmarshaler` when the key
+// type implements it, with `time.ParseDuration` for durations and with the
+// same rules as `nilo.Cast` otherwise.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/javiorfo/nilo"
+	"github.com/javiorfo/nilo/internal/cast"
+)
+
+// DefaultSource is the source name reported for values coming from
+// `Key.Default`.
+const DefaultSource = "default"
+
+// ErrRequired is wrapped by the error reported for a required key that no
+// source provides.
+var ErrRequired = errors.New("required key is not set")
+
+// KeyError reports a key that could not be resolved.
+type KeyError struct {
+	// Key is the name of the key.
+	Key string
+	// Source is the name of the source holding the offending value, if any.
+	Source string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *KeyError) Error() string {
+	if e.Source == "" {
+		return fmt.Sprintf("config: %s: %v", e.Key, e.Err)
+	}
+	return fmt.Sprintf("config: %s (from %s): %v", e.Key, e.Source, e.Err)
+}
+
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
+
+// Entry describes how a key was resolved, as reported by `Resolver.Describe`.
+type Entry struct {
+	// Key is the name of the key.
+	Key string
+	// Value is the formatted value, empty when unset and masked for secrets.
+	Value string
+	// Source is the name of the source that provided the value, empty when
+	// unset.
+	Source string
+	// Err is the error resolving the key, if any.
+	Err error
+}
+
+// Resolver holds the ordered sources and the keys defined on them.
+type Resolver struct {
+	sources []Source
+	keys    []describer
+}
+
+type describer interface {
+	describe() Entry
+}
+
+// New returns a `Resolver` over sources, given from the highest precedence
+// to the lowest.
+func New(sources ...Source) *Resolver {
+	return &Resolver{sources: sources}
+}
+
+// Validate resolves every key and returns the `*KeyError`s, such as unset
+// required keys or values that do not parse, joined with `errors.Join`.
+func (r *Resolver) Validate() error {
+	var errs []error
+	for _, k := range r.keys {
+		if e := k.describe(); e.Err != nil {
+			errs = append(errs, e.Err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Describe resolves every key, in definition order, and reports where its
+// value came from.
+func (r *Resolver) Describe() []Entry {
+	entries := make([]Entry, len(r.keys))
+	for i, k := range r.keys {
+		entries[i] = k.describe()
+	}
+	return entries
+}
+
+// Print writes one line per key to w in the form `key = value (source)`,
+// for `--print-config` style debugging.
+func (r *Resolver) Print(w io.Writer) error {
+	for _, e := range r.Describe() {
+		var err error
+		switch {
+		case e.Err != nil:
+			_, err = fmt.Fprintf(w, "%s = <error: %v>\n", e.Key, e.Err)
+		case e.Source == "":
+			_, err = fmt.Fprintf(w, "%s = <unset>\n", e.Key)
+		default:
+			_, err = fmt.Fprintf(w, "%s = %s (%s)\n", e.Key, e.Value, e.Source)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Key is a typed configuration key.
+type Key[T any] struct {
+	r        *Resolver
+	name     string
+	def      nilo.Option[T]
+	required bool
+	secret   bool
+}
+
+// Define defines the key name of type `T` on r.
+func Define[T any](r *Resolver, name string) *Key[T] {
+	k := &Key[T]{r: r, name: name}
+	r.keys = append(r.keys, k)
+	return k
+}
+
+// Default sets the value used when no source provides the key.
+func (k *Key[T]) Default(value T) *Key[T] {
+	k.def = nilo.Value(value)
+	return k
+}
+
+// Required makes `Resolver.Validate` fail when no source provides the key.
+func (k *Key[T]) Required() *Key[T] {
+	k.required = true
+	return k
+}
+
+// Secret masks the value of the key in `Resolver.Describe` and
+// `Resolver.Print`, and keeps it out of the errors of values that do not
+// parse.
+func (k *Key[T]) Secret() *Key[T] {
+	k.secret = true
+	return k
+}
+
+// Name returns the name of the key.
+func (k *Key[T]) Name() string {
+	return k.name
+}
+
+// Get returns the resolved value of the key. It is `Nil` when no source
+// provides it or when the winning value does not parse.
+func (k *Key[T]) Get() nilo.Option[T] {
+	v, _, _ := k.Resolve()
+	return v
+}
+
+// Resolve walks the sources in order and returns the first value found,
+// along with the name of the source that provided it.
+//
+// A value that does not parse stops the walk with a `*KeyError`, instead of
+// silently falling back to a lower precedence source. For secret keys, the
+// error does not include the raw value.
+func (k *Key[T]) Resolve() (nilo.Option[T], string, error) {
+	for _, src := range k.r.sources {
+		raw := src.Lookup(k.name)
+		if raw.IsNil() {
+			continue
+		}
+
+		v, err := cast.FromText(raw.AsValue(), reflect.TypeFor[T]())
+		if err != nil {
+			if k.secret {
+				// Parse errors usually quote the raw value.
+				err = fmt.Errorf("cannot convert secret value to %s", reflect.TypeFor[T]())
+			}
+			return nilo.Nil[T](), src.Name(), &KeyError{Key: k.name, Source: src.Name(), Err: err}
+		}
+		return nilo.Value(v.Interface().(T)), src.Name(), nil
+	}
+
+	if k.def.IsValue() {
+		return k.def, DefaultSource, nil
+	}
+	if k.required {
+		return nilo.Nil[T](), "", &KeyError{Key: k.name, Err: ErrRequired}
+	}
+	return nilo.Nil[T](), "", nil
+}
+
+func (k *Key[T]) describe() Entry {
+	v, source, err := k.Resolve()
+	e := Entry{Key: k.name, Err: err}
+	if v.IsValue() {
+		e.Source = source
+		e.Value, _ = cast.ToText(reflect.ValueOf(v.AsValue()))
+		if k.secret {
+			e.Value = "********"
+		}
+	}
+	return e
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolver(t *testing.T) {
+	file := Map("file", map[string]string{"port": "7000", "host": "file-host", "timeout": "5s"})
+	env := Map("env", map[string]string{"port": "9090", "retries": "many"})
+
+	t.Run("Resolve", func(t *testing.T) {
+		t.Run("first source wins", func(t *testing.T) {
+			r := New(env, file)
+			port := Define[int](r, "port").Default(8080)
+
+			value, source, err := port.Resolve()
+
+			assert.NoError(t, err)
+			assert.Equal(t, 9090, value.AsValue())
+			assert.Equal(t, "env", source)
+		})
+
+		t.Run("lower sources fill the gaps", func(t *testing.T) {
+			r := New(env, file)
+
+			assert.Equal(t, "file-host", Define[string](r, "host").Get().AsValue())
+			assert.Equal(t, 5*time.Second, Define[time.Duration](r, "timeout").Get().AsValue())
+		})
+
+		t.Run("defaults come last", func(t *testing.T) {
+			r := New(env, file)
+
+			value, source, err := Define[bool](r, "debug").Default(true).Resolve()
+
+			assert.NoError(t, err)
+			assert.True(t, value.AsValue())
+			assert.Equal(t, DefaultSource, source)
+		})
+
+		t.Run("unset keys are Nil", func(t *testing.T) {
+			r := New(env, file)
+
+			value, source, err := Define[string](r, "missing").Resolve()
+
+			assert.NoError(t, err)
+			assert.True(t, value.IsNil())
+			assert.Equal(t, "", source)
+		})
+
+		t.Run("values that do not parse fail", func(t *testing.T) {
+			r := New(env, file)
+
+			value, source, err := Define[int](r, "retries").Default(3).Resolve()
+
+			assert.EqualError(t, err, `config: retries (from env): cannot convert "many" to int`)
+			assert.True(t, value.IsNil())
+			assert.Equal(t, "env", source)
+		})
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		r := New(env, file)
+		Define[int](r, "port")
+		Define[string](r, "token").Required()
+		Define[int](r, "retries")
+
+		err := r.Validate()
+
+		assert.ErrorIs(t, err, ErrRequired)
+		assert.ErrorContains(t, err, "config: token: required key is not set")
+		assert.ErrorContains(t, err, "config: retries (from env)")
+
+		var keyErr *KeyError
+		assert.ErrorAs(t, err, &keyErr)
+	})
+
+	t.Run("Print", func(t *testing.T) {
+		var buf bytes.Buffer
+		r := New(env, file)
+		Define[int](r, "port")
+		Define[string](r, "host").Secret()
+		Define[string](r, "region").Default("us")
+		Define[string](r, "missing")
+		Define[int](r, "retries")
+
+		err := r.Print(&buf)
+
+		assert.NoError(t, err)
+		assert.Equal(t, `port = 9090 (env)
+host = ******** (file)
+region = us (default)
+missing = <unset>
+retries = <error: config: retries (from env): cannot convert "many" to int>
+`, buf.String())
+	})
+
+	t.Run("secrets that do not parse are redacted", func(t *testing.T) {
+		var buf bytes.Buffer
+		r := New(Map("env", map[string]string{"pin": "s3cr3t", "expiry": "s3cr3t"}))
+		Define[int](r, "pin").Secret()
+		Define[time.Time](r, "expiry").Secret()
+
+		err := r.Validate()
+		assert.NoError(t, r.Print(&buf))
+
+		assert.EqualError(t, err, `config: pin (from env): cannot convert secret value to int
+config: expiry (from env): cannot convert secret value to time.Time`)
+		assert.NotContains(t, buf.String(), "s3cr3t")
+		assert.Contains(t, buf.String(), "pin = <error: config: pin (from env): cannot convert secret value to int>")
+	})
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"strings"
+
+	"github.com/javiorfo/nilo"
+)
+
+// Source provides raw values for keys. `Lookup` returns `Nil` when the
+// source does not hold the key.
+type Source interface {
+	Name() string
+	Lookup(key string) nilo.Option[string]
+}
+
+type funcSource struct {
+	name   string
+	lookup func(string) nilo.Option[string]
+}
+
+func (s funcSource) Name() string {
+	return s.name
+}
+
+func (s funcSource) Lookup(key string) nilo.Option[string] {
+	return s.lookup(key)
+}
+
+// Func returns a `Source` named name backed by the lookup function.
+func Func(name string, lookup func(key string) nilo.Option[string]) Source {
+	return funcSource{name, lookup}
+}
+
+// Map returns a `Source` named name backed by values, such as the flattened
+// content of a config file.
+func Map(name string, values map[string]string) Source {
+	return Func(name, func(key string) nilo.Option[string] {
+		v, ok := values[key]
+		if !ok {
+			return nilo.Nil[string]()
+		}
+		return nilo.Value(v)
+	})
+}
+
+// Env returns a `Source` named `env` backed by the process environment.
+//
+// Keys are mapped to variable names by upper-casing them, replacing `.` and
+// `-` with `_` and adding prefix, so `db.host` with prefix `APP` reads
+// `APP_DB_HOST`.
+func Env(prefix string) Source {
+	return Func("env", func(key string) nilo.Option[string] {
+		v, ok := os.LookupEnv(EnvName(prefix, key))
+		if !ok {
+			return nilo.Nil[string]()
+		}
+		return nilo.Value(v)
+	})
+}
+
+// EnvName returns the environment variable `Env` reads for key.
+func EnvName(prefix, key string) string {
+	name := strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
+
+// Flags returns a `Source` named `flags` backed by fs. Only flags given on
+// the command line provide values, so flag defaults never shadow lower
+// precedence sources. Flags are looked up by key name.
+func Flags(fs *flag.FlagSet) Source {
+	return Func("flags", func(key string) nilo.Option[string] {
+		var value nilo.Option[string]
+		fs.Visit(func(f *flag.Flag) {
+			if f.Name == key {
+				value = nilo.Value(f.Value.String())
+			}
+		})
+		return value
+	})
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"testing"
+
+	"github.com/javiorfo/nilo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSource(t *testing.T) {
+	t.Run("Map", func(t *testing.T) {
+		src := Map("file", map[string]string{"port": "80"})
+
+		assert.Equal(t, "file", src.Name())
+		assert.Equal(t, nilo.Value("80"), src.Lookup("port"))
+		assert.True(t, src.Lookup("host").IsNil())
+	})
+
+	t.Run("Env", func(t *testing.T) {
+		t.Setenv("APP_DB_HOST", "")
+		src := Env("APP")
+
+		assert.Equal(t, "APP_DB_HOST", EnvName("APP", "db.host"))
+		assert.Equal(t, "LOG_LEVEL", EnvName("", "log-level"))
+		assert.Equal(t, nilo.Value(""), src.Lookup("db.host"))
+		assert.True(t, src.Lookup("db.port").IsNil())
+	})
+
+	t.Run("Flags", func(t *testing.T) {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		fs.Int("port", 80, "port")
+		fs.String("host", "localhost", "host")
+		assert.NoError(t, fs.Parse([]string{"-port", "0"}))
+		src := Flags(fs)
+
+		assert.Equal(t, nilo.Value("0"), src.Lookup("port"))
+		assert.True(t, src.Lookup("host").IsNil())
+	})
+
+	t.Run("Func", func(t *testing.T) {
+		src := Func("static", func(string) nilo.Option[string] { return nilo.Value("x") })
+
+		r := New(src)
+		assert.Equal(t, "x", Define[string](r, "any").Get().AsValue())
+	})
+}
@@ -0,0 +1,91 @@
+// Package csvx reads and writes CSV files as structs, mapping blank cells to
+// `nilo.Option` fields that are `Nil`.
+//
+// Columns are matched to struct fields by header name, which defaults to the
+// field name and can be customized with the `csv` struct tag:
+//
+//	type Row struct {
+//		Name  string              `csv:"name"`
+//		Age   nilo.Option[int]    `csv:"age"`
+//		Email nilo.Option[string] `csv:"email"`
+//		Notes string              `csv:"-"`
+//	}
+//
+// Cells are converted with `encoding.TextMarshaler` and
+// `encoding.TextUnmarshaler` when the field type implements them, durations
+// with `time.Duration.String` and `time.ParseDuration`, and with the same
+// rules as `nilo.Cast` otherwise, so written files read back. Empty cells, and
+// any of the `NilValues` sentinels, decode into a `Nil` `Option` and leave
+// other fields at their zero value.
+package csvx
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/javiorfo/nilo/internal/cast"
+	"github.com/javiorfo/nilo/internal/optreflect"
+)
+
+const tagName = "csv"
+
+// RowError reports a record that could not be decoded or encoded.
+type RowError struct {
+	// Line is the line of the record in the input, starting at 1. It is the
+	// record number, counting the header, when writing.
+	Line int
+	// Column is the header name of the offending cell, if any.
+	Column string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *RowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("csvx: line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("csvx: line %d, column %q: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// decodeCell stores the cell in v. isNil reports whether the cell is blank.
+func decodeCell(v reflect.Value, cell string, isNil bool) error {
+	if isNil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	if optreflect.Is(v.Type()) {
+		inner := reflect.New(optreflect.Elem(v.Type())).Elem()
+		if err := decodeCell(inner, cell, false); err != nil {
+			return err
+		}
+		optreflect.Set(v, inner)
+		return nil
+	}
+
+	c, err := cast.FromText(cell, v.Type())
+	if err != nil {
+		return err
+	}
+	v.Set(c)
+	return nil
+}
+
+// encodeCell formats v as a cell. The boolean result is false for a `Nil`
+// `Option`.
+func encodeCell(v reflect.Value) (string, bool, error) {
+	if optreflect.Is(v.Type()) {
+		inner, ok := optreflect.Get(v)
+		if !ok {
+			return "", false, nil
+		}
+		return encodeCell(inner)
+	}
+
+	s, err := cast.ToText(v)
+	return s, true, err
+}
@@ -0,0 +1,165 @@
+package csvx
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/javiorfo/nilo"
+	"github.com/stretchr/testify/assert"
+)
+
+type person struct {
+	Name   string               `csv:"name"`
+	Age    nilo.Option[int]     `csv:"age"`
+	Email  nilo.Option[string]  `csv:"email"`
+	Score  nilo.Option[float64] `csv:"score"`
+	Active bool                 `csv:"active"`
+	Secret string               `csv:"-"`
+}
+
+func TestReader(t *testing.T) {
+	t.Run("Read", func(t *testing.T) {
+		t.Run("decodes cells by header name", func(t *testing.T) {
+			input := "email,name,age,score,active,extra\njohn@mail.com,John,30,9.5,true,x\n"
+			r := NewReader(strings.NewReader(input))
+			var result person
+
+			err := r.Read(&result)
+
+			assert.NoError(t, err)
+			assert.Equal(t, "John", result.Name)
+			assert.Equal(t, 30, result.Age.AsValue())
+			assert.Equal(t, "john@mail.com", result.Email.AsValue())
+			assert.Equal(t, 9.5, result.Score.AsValue())
+			assert.True(t, result.Active)
+			assert.ErrorIs(t, r.Read(&result), io.EOF)
+		})
+
+		t.Run("empty and sentinel cells become Nil", func(t *testing.T) {
+			input := "name,age,email,score\nJohn,,N/A,-\n"
+			r := NewReader(strings.NewReader(input))
+			r.NilValues = []string{"N/A", "-"}
+			result := person{Age: nilo.Value(1)}
+
+			err := r.Read(&result)
+
+			assert.NoError(t, err)
+			assert.True(t, result.Age.IsNil())
+			assert.True(t, result.Email.IsNil())
+			assert.True(t, result.Score.IsNil())
+		})
+
+		t.Run("conversion errors report line and column", func(t *testing.T) {
+			input := "name,age\nJohn,30\n\"Jane\nDoe\",abc\n"
+			r := NewReader(strings.NewReader(input))
+			var result person
+
+			assert.NoError(t, r.Read(&result))
+			err := r.Read(&result)
+
+			var rowErr *RowError
+			assert.ErrorAs(t, err, &rowErr)
+			assert.Equal(t, 3, rowErr.Line)
+			assert.Equal(t, "age", rowErr.Column)
+			assert.Equal(t, `csvx: line 3, column "age": cannot convert "abc" to int`, err.Error())
+		})
+
+		t.Run("invalid destination returns an error", func(t *testing.T) {
+			r := NewReader(strings.NewReader("name\nJohn\n"))
+
+			assert.Error(t, r.Read(person{}))
+		})
+	})
+
+	t.Run("ReadAll", func(t *testing.T) {
+		input := "name,age\nJohn,30\nJane,abc\nJim,\nJoe,x\n"
+
+		rows, err := ReadAll[person](NewReader(strings.NewReader(input)))
+
+		assert.Len(t, rows, 2)
+		assert.Equal(t, "John", rows[0].Name)
+		assert.True(t, rows[1].Age.IsNil())
+
+		var rowErr *RowError
+		assert.ErrorAs(t, err, &rowErr)
+		assert.Equal(t, 3, rowErr.Line)
+		assert.Contains(t, err.Error(), "line 5")
+	})
+}
+
+func TestWriter(t *testing.T) {
+	t.Run("Write", func(t *testing.T) {
+		t.Run("writes a header and empty cells for Nil", func(t *testing.T) {
+			var buf bytes.Buffer
+			rows := []person{
+				{Name: "John", Age: nilo.Value(30), Score: nilo.Value(9.5), Active: true},
+				{Name: "Jane"},
+			}
+
+			err := WriteAll(NewWriter(&buf), rows)
+
+			assert.NoError(t, err)
+			assert.Equal(t, "name,age,email,score,active\nJohn,30,,9.5,true\nJane,,,,false\n", buf.String())
+		})
+
+		t.Run("NilValue replaces empty cells", func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewWriter(&buf)
+			w.NilValue = "N/A"
+
+			err := WriteAll(w, []person{{Name: "Jane"}})
+
+			assert.NoError(t, err)
+			assert.Equal(t, "name,age,email,score,active\nJane,N/A,N/A,N/A,false\n", buf.String())
+		})
+
+		t.Run("rows of another type report their line", func(t *testing.T) {
+			w := NewWriter(io.Discard)
+
+			assert.NoError(t, w.Write(person{}))
+			err := w.Write(struct{ Name string }{})
+
+			var rowErr *RowError
+			assert.True(t, errors.As(err, &rowErr))
+			assert.Equal(t, 3, rowErr.Line)
+		})
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		var buf bytes.Buffer
+		input := []person{
+			{Name: "John", Age: nilo.Value(0), Email: nilo.Value("john@mail.com")},
+			{Name: "Jane", Score: nilo.Value(-1.25)},
+		}
+
+		assert.NoError(t, WriteAll(NewWriter(&buf), input))
+		result, err := ReadAll[person](NewReader(&buf))
+
+		assert.NoError(t, err)
+		assert.Equal(t, input, result)
+	})
+
+	t.Run("round trip of times, durations and float32", func(t *testing.T) {
+		type event struct {
+			At      nilo.Option[time.Time]     `csv:"at"`
+			Timeout nilo.Option[time.Duration] `csv:"timeout"`
+			Ratio   float32                    `csv:"ratio"`
+		}
+		var buf bytes.Buffer
+		input := []event{
+			{At: nilo.Value(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)), Timeout: nilo.Value(1500 * time.Millisecond), Ratio: 0.1},
+			{Ratio: -2.5},
+		}
+
+		assert.NoError(t, WriteAll(NewWriter(&buf), input))
+		assert.Equal(t, "at,timeout,ratio\n2024-01-02T03:04:05Z,1.5s,0.1\n,,-2.5\n", buf.String())
+		result, err := ReadAll[event](NewReader(&buf))
+
+		assert.NoError(t, err)
+		assert.Equal(t, input, result)
+	})
+}
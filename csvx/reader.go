@@ -0,0 +1,122 @@
+package csvx
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+
+	"github.com/javiorfo/nilo/internal/fields"
+)
+
+// Reader decodes the records of a CSV file into structs. The first record
+// is the header.
+type Reader struct {
+	// CSV is the underlying reader, exposed to configure its delimiter,
+	// comment character and quoting rules before the first call to Read.
+	CSV *csv.Reader
+	// NilValues lists cell values, besides the empty string, that mean
+	// "unknown", such as "N/A" or "-".
+	NilValues []string
+
+	header []string
+}
+
+// NewReader returns a new `Reader` that reads from r.
+func NewReader(r io.Reader) *Reader {
+	c := csv.NewReader(r)
+	c.FieldsPerRecord = -1
+	return &Reader{CSV: c}
+}
+
+// Header returns the header record, reading it if needed.
+func (r *Reader) Header() ([]string, error) {
+	if r.header != nil {
+		return r.header, nil
+	}
+
+	header, err := r.CSV.Read()
+	if err != nil {
+		return nil, err
+	}
+	r.header = slices.Clone(header)
+	return r.header, nil
+}
+
+// Read decodes the next record into the struct pointed to by dst.
+//
+// Columns without a matching field are ignored and fields without a matching
+// column are left untouched. At the end of the input, Read returns `io.EOF`.
+// Decoding failures are reported as a `*RowError`.
+func (r *Reader) Read(dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("csvx: Read requires a non-nil pointer to a struct")
+	}
+
+	header, err := r.Header()
+	if err != nil {
+		return err
+	}
+
+	record, err := r.CSV.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return &RowError{Line: parseErr.Line, Err: parseErr.Err}
+		}
+		return err
+	}
+	line, _ := r.CSV.FieldPos(0)
+
+	if len(record) > len(header) {
+		return &RowError{Line: line, Err: fmt.Errorf("record has %d fields, header has %d", len(record), len(header))}
+	}
+
+	v := rv.Elem()
+	fs := fields.Of(v.Type(), tagName)
+	for i, cell := range record {
+		f, ok := fields.ByName(fs, header[i])
+		if !ok {
+			continue
+		}
+		if err := decodeCell(v.FieldByIndex(f.Index), cell, r.isNil(cell)); err != nil {
+			return &RowError{Line: line, Column: header[i], Err: err}
+		}
+	}
+	return nil
+}
+
+func (r *Reader) isNil(cell string) bool {
+	return cell == "" || slices.Contains(r.NilValues, cell)
+}
+
+// ReadAll decodes every remaining record of r into a `T`, which must be a
+// struct type.
+//
+// Records that fail to decode are skipped and their `*RowError`s are joined
+// into the returned error, so a single bad row does not hide the others.
+func ReadAll[T any](r *Reader) ([]T, error) {
+	var rows []T
+	var errs []error
+	for {
+		var row T
+		err := r.Read(&row)
+		if err == io.EOF {
+			break
+		}
+
+		var rowErr *RowError
+		switch {
+		case errors.As(err, &rowErr):
+			errs = append(errs, err)
+		case err != nil:
+			return rows, err
+		default:
+			rows = append(rows, row)
+		}
+	}
+	return rows, errors.Join(errs...)
+}
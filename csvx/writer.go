@@ -0,0 +1,93 @@
+package csvx
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/javiorfo/nilo/internal/fields"
+)
+
+// Writer encodes structs as the records of a CSV file. The header is
+// written before the first record.
+type Writer struct {
+	// CSV is the underlying writer, exposed to configure its delimiter and
+	// line endings before the first call to Write.
+	CSV *csv.Writer
+	// NilValue is the cell written for a `Nil` `Option`. It defaults to
+	// the empty string.
+	NilValue string
+
+	typ  reflect.Type
+	line int
+}
+
+// NewWriter returns a new `Writer` that writes to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{CSV: csv.NewWriter(w)}
+}
+
+// Write encodes the struct src, or the struct it points to, as a record.
+//
+// Every row must have the same type as the first one, which determines the
+// header. Failures are reported as a `*RowError`.
+func (w *Writer) Write(src any) error {
+	v := reflect.Indirect(reflect.ValueOf(src))
+	if v.Kind() != reflect.Struct {
+		return errors.New("csvx: Write requires a struct or a pointer to a struct")
+	}
+
+	fs := fields.Of(v.Type(), tagName)
+	if w.typ == nil {
+		header := make([]string, len(fs))
+		for i, f := range fs {
+			header[i] = f.Name
+		}
+		if err := w.write(header); err != nil {
+			return err
+		}
+		w.typ = v.Type()
+	} else if v.Type() != w.typ {
+		return &RowError{Line: w.line + 1, Err: fmt.Errorf("row type %s differs from the header row type %s", v.Type(), w.typ)}
+	}
+
+	record := make([]string, len(fs))
+	for i, f := range fs {
+		cell, ok, err := encodeCell(v.FieldByIndex(f.Index))
+		if err != nil {
+			return &RowError{Line: w.line + 1, Column: f.Name, Err: err}
+		}
+		if !ok {
+			cell = w.NilValue
+		}
+		record[i] = cell
+	}
+	return w.write(record)
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (w *Writer) Flush() error {
+	w.CSV.Flush()
+	return w.CSV.Error()
+}
+
+func (w *Writer) write(record []string) error {
+	w.line++
+	if err := w.CSV.Write(record); err != nil {
+		return &RowError{Line: w.line, Err: err}
+	}
+	return nil
+}
+
+// WriteAll writes the header and a record for each row to w, then flushes.
+// Nothing but the flush happens when rows is empty.
+func WriteAll[T any](w *Writer, rows []T) error {
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,210 @@
+// Package env loads configuration structs from environment variables,
+// using `nilo.Option` to tell an unset variable from an empty one.
+//
+// Fields are bound to variables with the `env` struct tag, and the `nilo`
+// struct tag adds defaults and requirements:
+//
+//	type Config struct {
+//		Port    nilo.Option[int]           `env:"PORT" nilo:"default=8080"`
+//		Token   string                     `env:"TOKEN" nilo:"required"`
+//		Timeout nilo.Option[time.Duration] `env:"TIMEOUT"`
+//		DB      DB                         `env:"DB_"`
+//	}
+//
+// An unset variable leaves its field untouched, so fresh `Option` fields stay
+// `Nil`, while a set variable, even to the empty string, makes it `Value`.
+// Values are parsed with `encoding.TextUnmarshaler` when the field type
+// implements it, with `time.ParseDuration` for durations and with the same
+// rules as `nilo.Cast` otherwise. The `env` tag of a nested struct is a
+// prefix for the variables of its fields.
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/javiorfo/nilo"
+	"github.com/javiorfo/nilo/internal/cast"
+	"github.com/javiorfo/nilo/internal/fields"
+	"github.com/javiorfo/nilo/internal/optreflect"
+)
+
+// ErrRequired is wrapped by the error reported for an unset variable whose
+// field is tagged `nilo:"required"`.
+var ErrRequired = errors.New("required variable is not set")
+
+// Source looks up environment variables. It is satisfied by `OS` and `Map`,
+// and lets tests inject a fake environment.
+type Source interface {
+	LookupEnv(name string) (string, bool)
+}
+
+// OS is the `Source` backed by the process environment.
+type OS struct{}
+
+// LookupEnv calls `os.LookupEnv`.
+func (OS) LookupEnv(name string) (string, bool) {
+	return os.LookupEnv(name)
+}
+
+// Map is a `Source` backed by a map, handy in tests.
+type Map map[string]string
+
+// LookupEnv returns the entry for name.
+func (m Map) LookupEnv(name string) (string, bool) {
+	v, ok := m[name]
+	return v, ok
+}
+
+// VarError reports a variable that could not be loaded into a field.
+type VarError struct {
+	// Name is the environment variable.
+	Name string
+	// Path is the Go path of the field, like `DB.Host`.
+	Path string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *VarError) Error() string {
+	return fmt.Sprintf("env: %s (field %s): %v", e.Name, e.Path, e.Err)
+}
+
+func (e *VarError) Unwrap() error {
+	return e.Err
+}
+
+// Load fills the struct pointed to by cfg from the process environment.
+func Load(cfg any) error {
+	return LoadFrom(OS{}, cfg)
+}
+
+// MustLoad is like `Load` but panics if any variable fails to load.
+func MustLoad(cfg any) {
+	if err := Load(cfg); err != nil {
+		panic(err)
+	}
+}
+
+// LoadFrom fills the struct pointed to by cfg from src.
+//
+// Every field is attempted; failures, including unset required variables,
+// are returned as `*VarError`s joined with `errors.Join`.
+func LoadFrom(src Source, cfg any) error {
+	rv := reflect.ValueOf(cfg)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("env: Load requires a non-nil pointer to a struct")
+	}
+
+	l := loader{src: src}
+	l.loadStruct(rv.Elem(), "", "")
+	return errors.Join(l.errs...)
+}
+
+// Lookup returns the variable name from the process environment parsed as
+// a `T`. It is `Nil` when the variable is unset or does not parse.
+func Lookup[T any](name string) nilo.Option[T] {
+	return LookupFrom[T](OS{}, name)
+}
+
+// LookupFrom returns the variable name from src parsed as a `T`. It is `Nil`
+// when the variable is unset or does not parse.
+func LookupFrom[T any](src Source, name string) nilo.Option[T] {
+	raw, ok := src.LookupEnv(name)
+	if !ok {
+		return nilo.Nil[T]()
+	}
+
+	v, err := cast.FromText(raw, reflect.TypeFor[T]())
+	if err != nil {
+		return nilo.Nil[T]()
+	}
+	return nilo.Value(v.Interface().(T))
+}
+
+type loader struct {
+	src  Source
+	errs []error
+}
+
+func (l *loader) loadStruct(v reflect.Value, prefix, path string) {
+	for _, f := range fields.Of(v.Type(), "env") {
+		fv := v.FieldByIndex(f.Index)
+		fpath := f.GoName
+		if path != "" {
+			fpath = path + "." + f.GoName
+		}
+
+		if fv.Kind() == reflect.Struct && !optreflect.Is(f.Type) && !cast.IsText(f.Type) {
+			nested := prefix
+			if f.Tagged {
+				nested += f.Name
+			}
+			l.loadStruct(fv, nested, fpath)
+			continue
+		}
+		if !f.Tagged {
+			continue
+		}
+
+		name := prefix + f.Name
+		if err := l.loadField(fv, name, f.Tag.Get("nilo")); err != nil {
+			l.errs = append(l.errs, &VarError{Name: name, Path: fpath, Err: err})
+		}
+	}
+}
+
+func (l *loader) loadField(v reflect.Value, name, tag string) error {
+	def, hasDef, required := parseTag(tag)
+
+	raw, ok := l.src.LookupEnv(name)
+	switch {
+	case ok:
+	case hasDef:
+		raw = def
+	case required:
+		return ErrRequired
+	default:
+		return nil
+	}
+
+	return set(v, raw)
+}
+
+func set(v reflect.Value, raw string) error {
+	if optreflect.Is(v.Type()) {
+		inner := reflect.New(optreflect.Elem(v.Type())).Elem()
+		if err := set(inner, raw); err != nil {
+			return err
+		}
+		optreflect.Set(v, inner)
+		return nil
+	}
+
+	c, err := cast.FromText(raw, v.Type())
+	if err != nil {
+		return err
+	}
+	v.Set(c)
+	return nil
+}
+
+// parseTag reads the `nilo` tag options. The default value runs to the end
+// of the tag, so it may contain commas.
+func parseTag(tag string) (def string, hasDef, required bool) {
+	for tag != "" {
+		if d, ok := strings.CutPrefix(tag, "default="); ok {
+			return d, true, required
+		}
+
+		var opt string
+		opt, tag, _ = strings.Cut(tag, ",")
+		if opt == "required" {
+			required = true
+		}
+	}
+	return "", false, required
+}
@@ -0,0 +1,98 @@
+package env
+
+import (
+	"testing"
+	"time"
+
+	"github.com/javiorfo/nilo"
+	"github.com/stretchr/testify/assert"
+)
+
+type db struct {
+	Host nilo.Option[string] `env:"HOST"`
+	Port int                 `env:"PORT" nilo:"default=5432"`
+}
+
+type config struct {
+	Port    nilo.Option[int]           `env:"PORT" nilo:"default=8080"`
+	Token   string                     `env:"TOKEN" nilo:"required"`
+	Name    nilo.Option[string]        `env:"NAME"`
+	Timeout nilo.Option[time.Duration] `env:"TIMEOUT"`
+	Hosts   nilo.Option[string]        `env:"HOSTS" nilo:"default=a,b"`
+	DB      db                         `env:"DB_"`
+	Debug   bool
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("unset variables stay Nil and defaults apply", func(t *testing.T) {
+		var result config
+
+		err := LoadFrom(Map{"TOKEN": "secret"}, &result)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 8080, result.Port.AsValue())
+		assert.Equal(t, "secret", result.Token)
+		assert.True(t, result.Name.IsNil())
+		assert.True(t, result.Timeout.IsNil())
+		assert.Equal(t, "a,b", result.Hosts.AsValue())
+		assert.True(t, result.DB.Host.IsNil())
+		assert.Equal(t, 5432, result.DB.Port)
+	})
+
+	t.Run("set variables become Value, even when empty", func(t *testing.T) {
+		var result config
+		src := Map{"TOKEN": "secret", "PORT": "9000", "NAME": "", "TIMEOUT": "5s", "DB_HOST": "db", "DB_PORT": "1"}
+
+		err := LoadFrom(src, &result)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 9000, result.Port.AsValue())
+		assert.Equal(t, "", result.Name.AsValue())
+		assert.Equal(t, "db", result.DB.Host.AsValue())
+		assert.Equal(t, 1, result.DB.Port)
+	})
+
+	t.Run("required and invalid variables fail", func(t *testing.T) {
+		var result config
+
+		err := LoadFrom(Map{"PORT": "http", "DB_PORT": "x"}, &result)
+
+		assert.ErrorIs(t, err, ErrRequired)
+		assert.ErrorContains(t, err, "env: TOKEN (field Token): required variable is not set")
+		assert.ErrorContains(t, err, `env: PORT (field Port): cannot convert "http" to int`)
+		assert.ErrorContains(t, err, "env: DB_PORT (field DB.Port)")
+
+		var varErr *VarError
+		assert.ErrorAs(t, err, &varErr)
+		assert.True(t, result.Port.IsNil())
+	})
+
+	t.Run("process environment", func(t *testing.T) {
+		t.Setenv("TOKEN", "from-os")
+		var result config
+
+		assert.NotPanics(t, func() { MustLoad(&result) })
+		assert.Equal(t, "from-os", result.Token)
+	})
+
+	t.Run("invalid destination returns an error", func(t *testing.T) {
+		assert.Error(t, LoadFrom(Map{}, config{}))
+	})
+}
+
+func TestLookup(t *testing.T) {
+	t.Run("LookupFrom", func(t *testing.T) {
+		src := Map{"PORT": "80", "BAD": "x", "EMPTY": ""}
+
+		assert.Equal(t, 80, LookupFrom[int](src, "PORT").AsValue())
+		assert.True(t, LookupFrom[int](src, "BAD").IsNil())
+		assert.True(t, LookupFrom[int](src, "MISSING").IsNil())
+		assert.Equal(t, "", LookupFrom[string](src, "EMPTY").AsValue())
+	})
+
+	t.Run("Lookup", func(t *testing.T) {
+		t.Setenv("NILO_TIMEOUT", "2m")
+
+		assert.Equal(t, 2*time.Minute, Lookup[time.Duration]("NILO_TIMEOUT").AsValue())
+	})
+}
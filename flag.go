@@ -0,0 +1,85 @@
+package nilo
+
+import (
+	"flag"
+	"reflect"
+	"time"
+
+	"github.com/javiorfo/nilo/internal/cast"
+)
+
+// FlagValue adapts an `Option` to the `flag.Value` interface, so a flag
+// stays `Nil` unless it is given on the command line.
+//
+// It also implements the `Type` method of `pflag.Value`, so the same value
+// can be registered with pflag or cobra based CLIs:
+//
+//	var timeout nilo.Option[time.Duration]
+//	cmd.Flags().Var(nilo.NewFlagValue(&timeout), "timeout", "request timeout")
+type FlagValue[T any] struct {
+	opt *Option[T]
+}
+
+// NewFlagValue returns a `FlagValue` that stores parsed flags in opt.
+func NewFlagValue[T any](opt *Option[T]) *FlagValue[T] {
+	return &FlagValue[T]{opt}
+}
+
+// FlagVar defines a flag with the specified name and usage string in fs and
+// returns the `Option` that stores its value.
+//
+// The `Option` is `Nil` unless the flag is given, which tells `--timeout=0`
+// apart from a missing `--timeout`. Values are parsed with
+// `encoding.TextUnmarshaler` when `T` implements it, with
+// `time.ParseDuration` for durations and with the same rules as `Cast`
+// otherwise.
+//
+// Parameters:
+//   - fs: The flag set to define the flag in, e.g. `flag.CommandLine`.
+//   - name: The name of the flag.
+//   - usage: The usage string shown in the help message.
+func FlagVar[T any](fs *flag.FlagSet, name, usage string) *Option[T] {
+	opt := new(Option[T])
+	fs.Var(NewFlagValue(opt), name, usage)
+	return opt
+}
+
+// Set implements `flag.Value`. It parses s and makes the `Option` `Value`.
+func (f *FlagValue[T]) Set(s string) error {
+	v, err := cast.FromText(s, reflect.TypeFor[T]())
+	if err != nil {
+		return err
+	}
+	f.opt.Insert(v.Interface().(T))
+	return nil
+}
+
+// String implements `flag.Value`. It returns the empty string for a `Nil`
+// `Option` and the formatted value otherwise.
+func (f *FlagValue[T]) String() string {
+	if f == nil || f.opt == nil || f.opt.IsNil() {
+		return ""
+	}
+	s, _ := cast.ToText(reflect.ValueOf(f.opt.AsValue()))
+	return s
+}
+
+// Get implements `flag.Getter`. It returns the `Option`.
+func (f *FlagValue[T]) Get() any {
+	return *f.opt
+}
+
+// Type implements the `pflag.Value` interface. It returns the name of `T`,
+// which pflag shows in the help message.
+func (f *FlagValue[T]) Type() string {
+	t := reflect.TypeFor[T]()
+	if t == reflect.TypeFor[time.Duration]() {
+		return "duration"
+	}
+	return t.String()
+}
+
+// IsBoolFlag lets boolean flags be given without a value, as in `-verbose`.
+func (f *FlagValue[T]) IsBoolFlag() bool {
+	return reflect.TypeFor[T]().Kind() == reflect.Bool
+}
@@ -0,0 +1,77 @@
+package nilo
+
+import (
+	"flag"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlag(t *testing.T) {
+	newFlagSet := func() *flag.FlagSet {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		return fs
+	}
+
+	t.Run("FlagVar", func(t *testing.T) {
+		t.Run("when flags are not given", func(t *testing.T) {
+			fs := newFlagSet()
+			timeout := FlagVar[time.Duration](fs, "timeout", "request timeout")
+			name := FlagVar[string](fs, "name", "user name")
+
+			err := fs.Parse(nil)
+
+			assert.NoError(t, err)
+			assert.True(t, timeout.IsNil())
+			assert.True(t, name.IsNil())
+		})
+
+		t.Run("when flags are given with zero values", func(t *testing.T) {
+			fs := newFlagSet()
+			timeout := FlagVar[time.Duration](fs, "timeout", "request timeout")
+			retries := FlagVar[int](fs, "retries", "retries")
+			verbose := FlagVar[bool](fs, "verbose", "verbose output")
+
+			err := fs.Parse([]string{"--timeout=0s", "-retries", "0", "-verbose"})
+
+			assert.NoError(t, err)
+			assert.Equal(t, time.Duration(0), timeout.AsValue())
+			assert.Equal(t, 0, retries.AsValue())
+			assert.True(t, verbose.AsValue())
+		})
+
+		t.Run("when flags are invalid", func(t *testing.T) {
+			fs := newFlagSet()
+			retries := FlagVar[int](fs, "retries", "retries")
+
+			err := fs.Parse([]string{"-retries", "many"})
+
+			assert.Error(t, err)
+			assert.True(t, retries.IsNil())
+		})
+	})
+
+	t.Run("FlagValue", func(t *testing.T) {
+		t.Run("String, Get and Type", func(t *testing.T) {
+			opt := Nil[time.Duration]()
+			value := NewFlagValue(&opt)
+
+			assert.Equal(t, "", value.String())
+			assert.Equal(t, "duration", value.Type())
+			assert.NoError(t, value.Set("1m"))
+			assert.Equal(t, "1m0s", value.String())
+			assert.Equal(t, Value(time.Minute), value.Get())
+			assert.Equal(t, "int", NewFlagValue(new(Option[int])).Type())
+			assert.False(t, NewFlagValue(new(Option[int])).IsBoolFlag())
+		})
+
+		t.Run("zero value prints no default", func(t *testing.T) {
+			var value *FlagValue[int]
+
+			assert.Equal(t, "", value.String())
+		})
+	})
+}
@@ -0,0 +1,225 @@
+// Package form decodes URL query strings and form bodies into structs with
+// `nilo.Option` fields, and encodes them back.
+//
+// Keys are matched to struct fields by name, which defaults to the field
+// name and can be customized with the `form` struct tag:
+//
+//	type Search struct {
+//		Query  nilo.Option[string]   `form:"q"`
+//		MinAge nilo.Option[int]      `form:"min_age"`
+//		Tags   nilo.Option[[]string] `form:"tag"`
+//		Page   Page                  `form:"page"`
+//	}
+//
+// A missing key leaves its field untouched, so fresh `Option` fields stay
+// `Nil`, while a present key, even with an empty value, makes it `Value`.
+// Values are converted with `encoding.TextUnmarshaler` when the field type
+// implements it and with the same rules as `nilo.Cast` otherwise. Repeated
+// keys fill slice fields. Nested structs use dotted keys, like `page.size`.
+package form
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/javiorfo/nilo/internal/cast"
+	"github.com/javiorfo/nilo/internal/fields"
+	"github.com/javiorfo/nilo/internal/optreflect"
+)
+
+const tagName = "form"
+
+// FieldError reports a value that could not be decoded into a field.
+type FieldError struct {
+	// Path is the Go path of the field, like `Page.Size`.
+	Path string
+	// Key is the form key the value came from, like `page.size`.
+	Key string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("form: field %s (key %q): %v", e.Path, e.Key, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Decode stores values in the struct pointed to by dst.
+//
+// Every field is attempted; failures are returned as `*FieldError`s joined
+// with `errors.Join`.
+func Decode(values url.Values, dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("form: Decode requires a non-nil pointer to a struct")
+	}
+
+	d := decoder{values: values}
+	d.decodeStruct(rv.Elem(), "", "")
+	return errors.Join(d.errs...)
+}
+
+// Encode returns the form values of the struct src, or of the struct it
+// points to.
+//
+// `Nil` `Option`s and nil pointers are left out, slices become repeated keys
+// and nested structs become dotted keys. Encode returns nil if src is not a
+// struct.
+func Encode(src any) url.Values {
+	v := reflect.Indirect(reflect.ValueOf(src))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	values := url.Values{}
+	encodeStruct(values, v, "")
+	return values
+}
+
+type decoder struct {
+	values url.Values
+	errs   []error
+}
+
+func (d *decoder) decodeStruct(v reflect.Value, prefix, path string) {
+	for _, f := range fields.Of(v.Type(), tagName) {
+		d.decodeField(v.FieldByIndex(f.Index), prefix+f.Name, join(path, f.GoName))
+	}
+}
+
+// decodeField decodes the key into v if it is present.
+func (d *decoder) decodeField(v reflect.Value, key, path string) {
+	t := v.Type()
+	switch {
+	case optreflect.Is(t):
+		if !d.has(optreflect.Elem(t), key) {
+			return
+		}
+		inner := reflect.New(optreflect.Elem(t)).Elem()
+		errs := len(d.errs)
+		if d.decodeField(inner, key, path); len(d.errs) == errs {
+			optreflect.Set(v, inner)
+		}
+	case t.Kind() == reflect.Pointer:
+		if !d.has(t.Elem(), key) {
+			return
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		d.decodeField(v.Elem(), key, path)
+	case isNested(t):
+		d.decodeStruct(v, key+".", path)
+	default:
+		vs, ok := d.values[key]
+		if !ok {
+			return
+		}
+		if err := decodeValues(v, vs); err != nil {
+			d.errs = append(d.errs, &FieldError{Path: path, Key: key, Err: err})
+		}
+	}
+}
+
+// has reports whether the form holds a value for a field of type t.
+func (d *decoder) has(t reflect.Type, key string) bool {
+	for optreflect.Is(t) || t.Kind() == reflect.Pointer {
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		} else {
+			t = optreflect.Elem(t)
+		}
+	}
+
+	if !isNested(t) {
+		_, ok := d.values[key]
+		return ok
+	}
+	for k := range d.values {
+		if strings.HasPrefix(k, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeValues(v reflect.Value, vs []string) error {
+	t := v.Type()
+	if t.Kind() == reflect.Slice && !cast.IsText(t) && t.Elem().Kind() != reflect.Uint8 {
+		s := reflect.MakeSlice(t, len(vs), len(vs))
+		for i, raw := range vs {
+			if err := decodeValues(s.Index(i), []string{raw}); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+
+	if optreflect.Is(t) {
+		inner := reflect.New(optreflect.Elem(t)).Elem()
+		if err := decodeValues(inner, vs); err != nil {
+			return err
+		}
+		optreflect.Set(v, inner)
+		return nil
+	}
+
+	raw := ""
+	if len(vs) > 0 {
+		raw = vs[0]
+	}
+	c, err := cast.FromText(raw, t)
+	if err != nil {
+		return err
+	}
+	v.Set(c)
+	return nil
+}
+
+func encodeStruct(values url.Values, v reflect.Value, prefix string) {
+	for _, f := range fields.Of(v.Type(), tagName) {
+		encodeField(values, v.FieldByIndex(f.Index), prefix+f.Name)
+	}
+}
+
+func encodeField(values url.Values, v reflect.Value, key string) {
+	t := v.Type()
+	switch {
+	case optreflect.Is(t):
+		if inner, ok := optreflect.Get(v); ok {
+			encodeField(values, inner, key)
+		}
+	case t.Kind() == reflect.Pointer:
+		if !v.IsNil() {
+			encodeField(values, v.Elem(), key)
+		}
+	case isNested(t):
+		encodeStruct(values, v, key+".")
+	case t.Kind() == reflect.Slice && !cast.IsText(t) && t.Elem().Kind() != reflect.Uint8:
+		for i := range v.Len() {
+			encodeField(values, v.Index(i), key)
+		}
+	default:
+		if s, err := cast.ToText(v); err == nil {
+			values.Add(key, s)
+		}
+	}
+}
+
+func isNested(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !optreflect.Is(t) && !cast.IsText(t)
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,112 @@
+package form
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/javiorfo/nilo"
+	"github.com/stretchr/testify/assert"
+)
+
+type page struct {
+	Size   nilo.Option[int] `form:"size"`
+	Offset int              `form:"offset"`
+}
+
+type search struct {
+	Query  nilo.Option[string]    `form:"q"`
+	MinAge nilo.Option[int]       `form:"min_age"`
+	Tags   nilo.Option[[]string]  `form:"tag"`
+	Since  nilo.Option[time.Time] `form:"since"`
+	Active *bool                  `form:"active"`
+	Page   page                   `form:"page"`
+	Sort   nilo.Option[page]      `form:"sort"`
+	Secret string                 `form:"-"`
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("missing keys stay Nil", func(t *testing.T) {
+		var result search
+
+		err := Decode(url.Values{}, &result)
+
+		assert.NoError(t, err)
+		assert.True(t, result.Query.IsNil())
+		assert.True(t, result.MinAge.IsNil())
+		assert.True(t, result.Tags.IsNil())
+		assert.True(t, result.Sort.IsNil())
+		assert.Nil(t, result.Active)
+	})
+
+	t.Run("present keys become Value, even when empty", func(t *testing.T) {
+		var result search
+		values, _ := url.ParseQuery("q=&min_age=18&tag=a&tag=b&since=2024-01-02T00:00:00Z&active=true&page.size=10&sort.offset=2")
+
+		err := Decode(values, &result)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "", result.Query.AsValue())
+		assert.Equal(t, 18, result.MinAge.AsValue())
+		assert.Equal(t, []string{"a", "b"}, result.Tags.AsValue())
+		assert.Equal(t, 2024, result.Since.AsValue().Year())
+		assert.True(t, *result.Active)
+		assert.Equal(t, 10, result.Page.Size.AsValue())
+		assert.Equal(t, 2, result.Sort.AsValue().Offset)
+		assert.True(t, result.Sort.AsValue().Size.IsNil())
+	})
+
+	t.Run("errors report every field path", func(t *testing.T) {
+		var result search
+		values := url.Values{"min_age": {"old"}, "page.size": {"big"}, "sort.offset": {"x"}, "q": {"ok"}}
+
+		err := Decode(values, &result)
+
+		assert.ErrorContains(t, err, `form: field MinAge (key "min_age"): cannot convert "old" to int`)
+		assert.ErrorContains(t, err, `form: field Page.Size (key "page.size")`)
+		assert.ErrorContains(t, err, `form: field Sort.Offset (key "sort.offset")`)
+
+		var fieldErr *FieldError
+		assert.ErrorAs(t, err, &fieldErr)
+		assert.True(t, result.MinAge.IsNil())
+		assert.True(t, result.Sort.IsNil())
+		assert.Equal(t, "ok", result.Query.AsValue())
+	})
+
+	t.Run("invalid destination returns an error", func(t *testing.T) {
+		assert.Error(t, Decode(url.Values{}, search{}))
+	})
+}
+
+func TestEncode(t *testing.T) {
+	t.Run("Nil fields are left out", func(t *testing.T) {
+		result := Encode(search{})
+
+		assert.Equal(t, url.Values{"page.offset": {"0"}}, result)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		active := false
+		input := search{
+			Query:  nilo.Value("go"),
+			MinAge: nilo.Value(0),
+			Tags:   nilo.Value([]string{"a", "b"}),
+			Since:  nilo.Value(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)),
+			Active: &active,
+			Page:   page{Size: nilo.Value(5), Offset: 10},
+			Sort:   nilo.Value(page{Offset: 1}),
+		}
+		var result search
+
+		values := Encode(&input)
+		err := Decode(values, &result)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, values["tag"])
+		assert.Equal(t, input, result)
+	})
+
+	t.Run("non-struct returns nil", func(t *testing.T) {
+		assert.Nil(t, Encode(3))
+	})
+}
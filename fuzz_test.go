@@ -0,0 +1,180 @@
+package nilo
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+type fuzzRecord struct {
+	ID     Option[int]            `json:"id" xml:"id,attr"`
+	Name   Option[string]         `json:"name" xml:"name"`
+	Score  Option[float64]        `json:"score" xml:"score"`
+	Tags   Option[[]string]       `json:"tags" xml:"tag"`
+	Counts Option[map[int]string] `json:"counts" xml:"-"`
+	Nested Option[Option[bool]]   `json:"nested" xml:"nested"`
+}
+
+// jsonRoundTrip checks that data decoding into an Option of T encodes to a
+// stable form.
+func jsonRoundTrip[T any](t *testing.T, data []byte) {
+	var first Option[T]
+	if err := json.Unmarshal(data, &first); err != nil {
+		return
+	}
+	encoded, err := json.Marshal(first)
+	if err != nil {
+		t.Fatalf("Marshal %#v: %v", first, err)
+	}
+
+	var second Option[T]
+	if err := json.Unmarshal(encoded, &second); err != nil {
+		t.Fatalf("Unmarshal %s: %v", encoded, err)
+	}
+	reencoded, err := json.Marshal(second)
+	if err != nil {
+		t.Fatalf("Marshal %#v: %v", second, err)
+	}
+	if string(encoded) != string(reencoded) {
+		t.Fatalf("unstable encoding: %s != %s", encoded, reencoded)
+	}
+}
+
+func FuzzJSON(f *testing.F) {
+	for _, seed := range []string{
+		`null`, `1`, `-0`, `1e400`, `"a"`, `""`, `true`, `[]`, `[1,null]`, `{}`,
+		`{"1":"a","-2":"b"}`, `{"x":"a"}`,
+		`{"id":1,"name":null,"score":2.5,"tags":["a"],"counts":{"3":"c"},"nested":false}`,
+		`{"nested":null,"tags":null}`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		jsonRoundTrip[int](t, data)
+		jsonRoundTrip[uint8](t, data)
+		jsonRoundTrip[float64](t, data)
+		jsonRoundTrip[string](t, data)
+		jsonRoundTrip[bool](t, data)
+		jsonRoundTrip[[]int](t, data)
+		jsonRoundTrip[map[string]int](t, data)
+		jsonRoundTrip[map[int]string](t, data)
+		jsonRoundTrip[map[bool]int](t, data)
+		jsonRoundTrip[Option[int]](t, data)
+		jsonRoundTrip[fuzzRecord](t, data)
+		jsonRoundTrip[any](t, data)
+	})
+}
+
+func FuzzXML(f *testing.F) {
+	for _, seed := range []string{
+		`<fuzzRecord id="1"><name>a</name><score>2.5</score><tag>x</tag><tag>y</tag><nested>true</nested></fuzzRecord>`,
+		`<fuzzRecord><name xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:nil="true"/></fuzzRecord>`,
+		`<fuzzRecord id=""><nested></nested></fuzzRecord>`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var record fuzzRecord
+		if err := xml.Unmarshal(data, &record); err != nil {
+			return
+		}
+		if _, err := xml.Marshal(record); err != nil {
+			t.Fatalf("Marshal %#v: %v", record, err)
+		}
+	})
+}
+
+// castKinds are the targets checked by FuzzCast, one per supported kind.
+var castKinds = []func(any) (any, bool){
+	castTo[int], castTo[int8], castTo[int16], castTo[int32], castTo[int64],
+	castTo[uint], castTo[uint8], castTo[uint16], castTo[uint32], castTo[uint64],
+	castTo[float32], castTo[float64], castTo[bool], castTo[string],
+	castTo[[]byte], castTo[[]rune], castTo[[4]byte], castTo[*[4]byte], castTo[[0]int],
+	castTo[any], castTo[error], castTo[chan int], castTo[struct{}],
+}
+
+func castTo[T any](value any) (any, bool) {
+	o := Cast[T](value)
+	return o.AsPtr(), o.IsValue()
+}
+
+func FuzzCast(f *testing.F) {
+	f.Add("42", int64(-7), 3.5, true, []byte{1, 2, 3, 4})
+	f.Add("", int64(0), 0.0, false, []byte{})
+	f.Add("1e3", int64(1<<40), -1e300, true, []byte{9})
+	f.Add("true", int64(255), 256.0, false, []byte(nil))
+
+	f.Fuzz(func(t *testing.T, s string, i int64, fl float64, b bool, bs []byte) {
+		sources := []any{
+			s, i, int8(i), uint8(i), uint64(i), int(i), fl, float32(fl), b, bs,
+			[]rune(s), [2]byte{byte(i), byte(i >> 8)}, nil, &s, []int{int(i)},
+		}
+		for _, source := range sources {
+			for _, to := range castKinds {
+				to(source)
+			}
+		}
+
+		// Strings parsed into numbers and booleans format back to a value
+		// that parses the same.
+		for _, to := range castKinds[:13] {
+			parsed, ok := to(s)
+			if !ok {
+				continue
+			}
+			text := Cast[string](reflect.ValueOf(parsed).Elem().Interface())
+			again, ok := to(text.AsValue())
+			if !ok || !reflect.DeepEqual(parsed, again) {
+				t.Fatalf("%q parsed as %v does not round trip through %q", s, reflect.ValueOf(parsed).Elem(), text.AsValue())
+			}
+		}
+
+		// Numbers cast into integers keep their truncated value, and fail
+		// only when it is out of range instead of wrapping.
+		for _, source := range []any{i, int8(i), uint8(i), uint64(i), int(i), fl, float32(fl)} {
+			want, exact := exactInt(reflect.ValueOf(source))
+			for _, to := range castKinds[:10] {
+				result, ok := to(source)
+				rv := reflect.ValueOf(result)
+				minimum, maximum := intRange(rv.Type().Elem())
+				if fits := exact && want.Cmp(minimum) >= 0 && want.Cmp(maximum) <= 0; ok != fits {
+					t.Fatalf("Cast[%s](%T(%v)) is Value = %v, want %v", rv.Type().Elem(), source, source, ok, fits)
+				}
+				if got, _ := exactInt(rv.Elem()); ok && got.Cmp(want) != 0 {
+					t.Fatalf("Cast[%s](%T(%v)) = %v", rv.Type().Elem(), source, source, got)
+				}
+			}
+		}
+	})
+}
+
+// exactInt returns the value of the number v, truncated if it is a float,
+// and false for NaN, infinities and other kinds.
+func exactInt(v reflect.Value) (*big.Int, bool) {
+	switch {
+	case v.CanInt():
+		return big.NewInt(v.Int()), true
+	case v.CanUint():
+		return new(big.Int).SetUint64(v.Uint()), true
+	case v.CanFloat() && !math.IsNaN(v.Float()) && !math.IsInf(v.Float(), 0):
+		i, _ := big.NewFloat(math.Trunc(v.Float())).Int(nil)
+		return i, true
+	}
+	return nil, false
+}
+
+// intRange returns the bounds of the integer type t.
+func intRange(t reflect.Type) (*big.Int, *big.Int) {
+	one := big.NewInt(1)
+	bits := uint(t.Bits())
+	if t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uintptr {
+		return big.NewInt(0), new(big.Int).Sub(new(big.Int).Lsh(one, bits), one)
+	}
+	limit := new(big.Int).Lsh(one, bits-1)
+	return new(big.Int).Neg(limit), new(big.Int).Sub(limit, one)
+}
@@ -0,0 +1,282 @@
+// Package httpbind binds HTTP requests into structs, populating
+// `nilo.Option` fields only when the request actually supplies a value.
+//
+// The source of each field is given by its struct tag:
+//
+//	type UpdateUser struct {
+//		ID      int64                 `path:"id"`
+//		DryRun  nilo.Option[bool]     `query:"dry_run"`
+//		Tags    nilo.Option[[]string] `query:"tag"`
+//		TraceID nilo.Option[string]   `header:"X-Trace-Id"`
+//		Session nilo.Option[string]   `cookie:"session"`
+//		Name    nilo.Option[string]   `json:"name"`
+//		Email   nilo.Option[string]   `json:"email"`
+//	}
+//
+// Path, query, header and cookie values are converted with
+// `encoding.TextUnmarshaler` when the field type implements it and with the
+// same rules as `nilo.Cast` otherwise; repeated query keys and headers fill
+// slices. Fields tagged only with `json` are decoded from a JSON body with
+// `encoding/json`, so absent keys keep their `Option` `Nil`. Bodies are
+// read up to `DefaultMaxBodySize` bytes unless `WithMaxBodySize` says
+// otherwise.
+package httpbind
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/javiorfo/nilo/internal/cast"
+	"github.com/javiorfo/nilo/internal/fields"
+	"github.com/javiorfo/nilo/internal/optreflect"
+)
+
+// DefaultMaxBodySize is the number of body bytes `Bind` reads unless
+// `WithMaxBodySize` is given.
+const DefaultMaxBodySize = 1 << 20
+
+// Sources are the struct tags read by `Bind`, in binding order.
+var sources = []string{"path", "query", "header", "cookie"}
+
+type options struct {
+	maxBodySize int64
+}
+
+// BindOption configures `Bind`.
+type BindOption func(*options)
+
+// WithMaxBodySize sets the number of body bytes `Bind` reads. Larger bodies
+// fail with an `*Error` whose status is 413 Request Entity Too Large.
+func WithMaxBodySize(n int64) BindOption {
+	return func(o *options) {
+		o.maxBodySize = n
+	}
+}
+
+// FieldError describes one input that could not be bound.
+type FieldError struct {
+	// Source is where the input came from: path, query, header, cookie or
+	// body.
+	Source string `json:"source"`
+	// Name is the input name, such as the query key or JSON field.
+	Name string `json:"name"`
+	// Message explains the failure.
+	Message string `json:"message"`
+}
+
+// Error is returned by `Bind` when the request is invalid. It is meant to be
+// sent back to the client with the status given by `StatusCode`, for
+// example as JSON.
+type Error struct {
+	Fields []FieldError `json:"errors"`
+
+	tooLarge bool
+}
+
+func (e *Error) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = fmt.Sprintf("%s %q: %s", f.Source, f.Name, f.Message)
+	}
+	return "httpbind: " + strings.Join(msgs, "; ")
+}
+
+// StatusCode returns `http.StatusRequestEntityTooLarge` when the body
+// exceeds the size limit and `http.StatusBadRequest` otherwise.
+func (e *Error) StatusCode() int {
+	if e.tooLarge {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// Bind populates the struct pointed to by dst from r.
+//
+// A field is left untouched when its source lacks the key, so fresh `Option`
+// fields stay `Nil`. Every field is attempted and the failures are returned
+// together as an `*Error`; other errors, like a failure to read the body,
+// are returned as they are.
+//
+// Parameters:
+//   - r: The request to bind.
+//   - dst: A pointer to the struct to populate.
+//   - opts: Options such as `WithMaxBodySize`.
+func Bind(r *http.Request, dst any, opts ...BindOption) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("httpbind: Bind requires a non-nil pointer to a struct")
+	}
+
+	o := options{maxBodySize: DefaultMaxBodySize}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	v := rv.Elem()
+	var bindErr Error
+	for _, source := range sources {
+		for _, f := range fields.Of(v.Type(), source) {
+			if !f.Tagged {
+				continue
+			}
+			values, ok := lookup(r, source, f.Name)
+			if !ok {
+				continue
+			}
+			if err := set(v.FieldByIndex(f.Index), values); err != nil {
+				bindErr.Fields = append(bindErr.Fields, FieldError{source, f.Name, err.Error()})
+			}
+		}
+	}
+
+	if err := bindBody(r, v, o.maxBodySize, &bindErr); err != nil {
+		return err
+	}
+	if len(bindErr.Fields) > 0 {
+		return &bindErr
+	}
+	return nil
+}
+
+// lookup returns the values of the named input and whether it is present.
+func lookup(r *http.Request, source, name string) ([]string, bool) {
+	switch source {
+	case "path":
+		v := r.PathValue(name)
+		return []string{v}, v != ""
+	case "query":
+		vs, ok := r.URL.Query()[name]
+		return vs, ok
+	case "header":
+		vs := r.Header.Values(name)
+		return vs, len(vs) > 0
+	default:
+		c, err := r.Cookie(name)
+		if err != nil {
+			return nil, false
+		}
+		return []string{c.Value}, true
+	}
+}
+
+// bindBody decodes the JSON body into the fields tagged only with `json`.
+func bindBody(r *http.Request, v reflect.Value, limit int64, bindErr *Error) error {
+	var targets []fields.Field
+	for _, f := range fields.Of(v.Type(), "json") {
+		if f.Tagged && !hasSource(f.Tag) {
+			targets = append(targets, f)
+		}
+	}
+	if len(targets) == 0 || r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, limit))
+	var maxErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxErr):
+		bindErr.Fields = append(bindErr.Fields, FieldError{"body", "", fmt.Sprintf("body exceeds %d bytes", maxErr.Limit)})
+		bindErr.tooLarge = true
+		return nil
+	case err != nil:
+		return fmt.Errorf("httpbind: reading body: %w", err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		if mt, _, _ := mime.ParseMediaType(ct); mt != "application/json" && !strings.HasSuffix(mt, "+json") {
+			bindErr.Fields = append(bindErr.Fields, FieldError{"body", "Content-Type", "unsupported media type " + mt})
+			return nil
+		}
+	}
+
+	// Decoding into a struct holding only the body fields keeps keys naming
+	// path, query, header or cookie fields from failing on their types.
+	sfs := make([]reflect.StructField, len(targets))
+	for i, f := range targets {
+		_, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		sfs[i] = reflect.StructField{
+			Name: "F" + strconv.Itoa(i),
+			Type: f.Type,
+			Tag:  reflect.StructTag(`json:"` + f.Name + "," + opts + `"`),
+		}
+	}
+	// Seeding it with the current values leaves the fields whose keys are
+	// absent untouched.
+	decoded := reflect.New(reflect.StructOf(sfs))
+	for i, f := range targets {
+		decoded.Elem().Field(i).Set(v.FieldByIndex(f.Index))
+	}
+	if err := json.Unmarshal(body, decoded.Interface()); err != nil {
+		bindErr.Fields = append(bindErr.Fields, FieldError{"body", jsonName(err), err.Error()})
+		return nil
+	}
+	for i, f := range targets {
+		v.FieldByIndex(f.Index).Set(decoded.Elem().Field(i))
+	}
+	return nil
+}
+
+func hasSource(tag reflect.StructTag) bool {
+	for _, source := range sources {
+		if _, ok := tag.Lookup(source); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonName(err error) string {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return typeErr.Field
+	}
+	return ""
+}
+
+func set(v reflect.Value, values []string) error {
+	t := v.Type()
+	switch {
+	case optreflect.Is(t):
+		inner := reflect.New(optreflect.Elem(t)).Elem()
+		if err := set(inner, values); err != nil {
+			return err
+		}
+		optreflect.Set(v, inner)
+		return nil
+	case t.Kind() == reflect.Pointer:
+		elem := reflect.New(t.Elem())
+		if err := set(elem.Elem(), values); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	case t.Kind() == reflect.Slice && !cast.IsText(t) && t.Elem().Kind() != reflect.Uint8:
+		s := reflect.MakeSlice(t, len(values), len(values))
+		for i, raw := range values {
+			if err := set(s.Index(i), []string{raw}); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+
+	c, err := cast.FromText(values[0], t)
+	if err != nil {
+		return err
+	}
+	v.Set(c)
+	return nil
+}
@@ -0,0 +1,208 @@
+package httpbind
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/javiorfo/nilo"
+	"github.com/stretchr/testify/assert"
+)
+
+type updateUser struct {
+	ID      int64                      `path:"id"`
+	DryRun  nilo.Option[bool]          `query:"dry_run"`
+	Tags    nilo.Option[[]string]      `query:"tag"`
+	Timeout nilo.Option[time.Duration] `query:"timeout"`
+	TraceID nilo.Option[string]        `header:"X-Trace-Id"`
+	Session nilo.Option[string]        `cookie:"session"`
+	Name    nilo.Option[string]        `json:"name"`
+	Age     nilo.Option[int]           `json:"age"`
+	Ignored string                     `json:"-"`
+}
+
+func newRequest(target, body string) *http.Request {
+	var r *http.Request
+	if body == "" {
+		r = httptest.NewRequest(http.MethodPatch, target, nil)
+	} else {
+		r = httptest.NewRequest(http.MethodPatch, target, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+	}
+	r.SetPathValue("id", "42")
+	return r
+}
+
+func TestBind(t *testing.T) {
+	t.Run("all sources", func(t *testing.T) {
+		r := newRequest("/users/42?dry_run=true&tag=a&tag=b&timeout=2s", `{"name":"Ana","age":null}`)
+		r.Header.Set("X-Trace-Id", "abc")
+		r.AddCookie(&http.Cookie{Name: "session", Value: "s1"})
+
+		var result updateUser
+		err := Bind(r, &result)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(42), result.ID)
+		assert.Equal(t, nilo.Value(true), result.DryRun)
+		assert.Equal(t, []string{"a", "b"}, result.Tags.AsValue())
+		assert.Equal(t, nilo.Value(2*time.Second), result.Timeout)
+		assert.Equal(t, nilo.Value("abc"), result.TraceID)
+		assert.Equal(t, nilo.Value("s1"), result.Session)
+		assert.Equal(t, nilo.Value("Ana"), result.Name)
+		assert.True(t, result.Age.IsNil())
+	})
+
+	t.Run("missing inputs stay Nil", func(t *testing.T) {
+		var result updateUser
+		err := Bind(newRequest("/users/42", ""), &result)
+
+		assert.NoError(t, err)
+		assert.True(t, result.DryRun.IsNil())
+		assert.True(t, result.Tags.IsNil())
+		assert.True(t, result.TraceID.IsNil())
+		assert.True(t, result.Session.IsNil())
+		assert.True(t, result.Name.IsNil())
+	})
+
+	t.Run("empty query value is present", func(t *testing.T) {
+		type params struct {
+			Q nilo.Option[string] `query:"q"`
+		}
+		var result params
+		err := Bind(httptest.NewRequest(http.MethodGet, "/?q=", nil), &result)
+
+		assert.NoError(t, err)
+		assert.Equal(t, nilo.Value(""), result.Q)
+	})
+
+	t.Run("absent body keys keep defaults", func(t *testing.T) {
+		type settings struct {
+			Name  string              `json:"name"`
+			Age   int                 `json:"age"`
+			Theme nilo.Option[string] `json:"theme"`
+		}
+		result := settings{Name: "guest", Age: 30, Theme: nilo.Value("dark")}
+		err := Bind(newRequest("/", `{"name":"x"}`), &result)
+
+		assert.NoError(t, err)
+		assert.Equal(t, settings{Name: "x", Age: 30, Theme: nilo.Value("dark")}, result)
+	})
+
+	t.Run("body does not leak into other sources", func(t *testing.T) {
+		var result updateUser
+		err := Bind(newRequest("/users/42", `{"ID":7,"DryRun":true,"Ignored":"x"}`), &result)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(42), result.ID)
+		assert.True(t, result.DryRun.IsNil())
+		assert.Empty(t, result.Ignored)
+	})
+
+	t.Run("body keys of other sources are ignored whatever their type", func(t *testing.T) {
+		var result updateUser
+		err := Bind(newRequest("/users/42", `{"ID":"seven","DryRun":"nope","Ignored":1,"name":"Ana"}`), &result)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(42), result.ID)
+		assert.Equal(t, nilo.Value("Ana"), result.Name)
+	})
+
+	t.Run("body fields named by their Go name", func(t *testing.T) {
+		type patch struct {
+			Nick nilo.Option[string] `json:",omitempty"`
+		}
+		var result patch
+		err := Bind(newRequest("/", `{"Nick":"jj"}`), &result)
+
+		assert.NoError(t, err)
+		assert.Equal(t, nilo.Value("jj"), result.Nick)
+	})
+
+	t.Run("oversized bodies fail with 413", func(t *testing.T) {
+		var result updateUser
+		err := Bind(newRequest("/users/42", `{"name":"Ana Maria"}`), &result, WithMaxBodySize(8))
+
+		var bindErr *Error
+		assert.True(t, errors.As(err, &bindErr))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, bindErr.StatusCode())
+		assert.Equal(t, []FieldError{{"body", "", "body exceeds 8 bytes"}}, bindErr.Fields)
+		assert.True(t, result.Name.IsNil())
+
+		assert.NoError(t, Bind(newRequest("/users/42", `{"name":"Ana Maria"}`), &result, WithMaxBodySize(64)))
+		assert.Equal(t, nilo.Value("Ana Maria"), result.Name)
+	})
+
+	t.Run("body stays readable", func(t *testing.T) {
+		r := newRequest("/users/42", `{"name":"Ana"}`)
+		var result updateUser
+		assert.NoError(t, Bind(r, &result))
+
+		rest, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"name":"Ana"}`, string(rest))
+	})
+
+	t.Run("invalid inputs", func(t *testing.T) {
+		r := newRequest("/users/42?dry_run=maybe&timeout=soon", `{"age":"old"}`)
+		var result updateUser
+		err := Bind(r, &result)
+
+		var bindErr *Error
+		assert.True(t, errors.As(err, &bindErr))
+		assert.Equal(t, http.StatusBadRequest, bindErr.StatusCode())
+		assert.Len(t, bindErr.Fields, 3)
+		assert.Equal(t, "query", bindErr.Fields[0].Source)
+		assert.Equal(t, "dry_run", bindErr.Fields[0].Name)
+		assert.Equal(t, "timeout", bindErr.Fields[1].Name)
+		assert.Equal(t, "body", bindErr.Fields[2].Source)
+		assert.Equal(t, "age", bindErr.Fields[2].Name)
+		assert.Contains(t, err.Error(), `query "dry_run"`)
+	})
+
+	t.Run("unsupported content type", func(t *testing.T) {
+		r := newRequest("/users/42", `name=Ana`)
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		var result updateUser
+		err := Bind(r, &result)
+
+		var bindErr *Error
+		assert.True(t, errors.As(err, &bindErr))
+		assert.Equal(t, "body", bindErr.Fields[0].Source)
+	})
+
+	t.Run("requires a struct pointer", func(t *testing.T) {
+		var result updateUser
+		assert.Error(t, Bind(newRequest("/", ""), result))
+		assert.Error(t, Bind(newRequest("/", ""), (*updateUser)(nil)))
+	})
+
+	t.Run("with ServeMux", func(t *testing.T) {
+		type getItem struct {
+			ID   nilo.Option[int]  `path:"id"`
+			Full nilo.Option[bool] `query:"full"`
+		}
+		var result getItem
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) {
+			if err := Bind(r, &result); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			}
+		})
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items/9", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, nilo.Value(9), result.ID)
+		assert.True(t, result.Full.IsNil())
+
+		rec = httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items/x", nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -0,0 +1,234 @@
+// Package httpc provides HTTP client helpers decoding JSON responses into
+// `nilo.Option`.
+//
+// A 404 Not Found or 204 No Content response means there is no value and
+// yields a `Nil` `Option` with a `nil` error; any other non-2xx response is
+// returned as a `*StatusError`. The classification can be replaced per call
+// with `WithClassifier`.
+package httpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/javiorfo/nilo"
+)
+
+// DefaultMaxBodySize is the number of bytes of a response body decoded
+// unless `WithMaxBodySize` is given.
+const DefaultMaxBodySize = 10 << 20
+
+// maxErrorBody bounds the part of a failed response kept in `StatusError`.
+const maxErrorBody = 4 << 10
+
+// Outcome is the way a response is handled, as decided by a `Classifier`.
+type Outcome int
+
+const (
+	// Decode decodes the response body into a `Value`, or a `Nil` when the
+	// body is empty or `null`.
+	Decode Outcome = iota
+	// Absent discards the response body and yields a `Nil`.
+	Absent
+	// Fail discards the response body and returns a `*StatusError`.
+	Fail
+)
+
+// Classifier decides the `Outcome` of a response from its status code.
+type Classifier func(status int) Outcome
+
+// DefaultClassifier maps 204 and 404 to `Absent`, any other 2xx status to
+// `Decode` and everything else to `Fail`.
+func DefaultClassifier(status int) Outcome {
+	switch {
+	case status == http.StatusNoContent, status == http.StatusNotFound:
+		return Absent
+	case status >= 200 && status < 300:
+		return Decode
+	default:
+		return Fail
+	}
+}
+
+// StatusError is returned for responses classified as `Fail`.
+//
+// It has a `StatusCode` method, so `nilo.IsNotFound` recognizes a 404 when
+// a custom `Classifier` turns it into an error.
+type StatusError struct {
+	Method string
+	URL    string
+	Status int
+	// Body holds the beginning of the response body.
+	Body []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("httpc: %s %s: %d %s", e.Method, e.URL, e.Status, http.StatusText(e.Status))
+}
+
+// StatusCode returns the HTTP status code of the response.
+func (e *StatusError) StatusCode() int {
+	return e.Status
+}
+
+// RequestOption customizes a single call.
+type RequestOption func(*settings)
+
+type settings struct {
+	classify    Classifier
+	header      http.Header
+	maxBodySize int64
+}
+
+// WithClassifier replaces `DefaultClassifier` for the call.
+//
+// Parameters:
+//   - classify: The function deciding the `Outcome` of the response.
+func WithClassifier(classify Classifier) RequestOption {
+	return func(s *settings) {
+		s.classify = classify
+	}
+}
+
+// WithHeader adds a header to the request.
+//
+// Parameters:
+//   - key: The header name.
+//   - value: The header value.
+func WithHeader(key, value string) RequestOption {
+	return func(s *settings) {
+		s.header.Add(key, value)
+	}
+}
+
+// WithMaxBodySize sets the number of bytes of the response body read for
+// the call. Larger bodies fail instead of being decoded, and the bodies of
+// absent and failed responses are drained only up to it.
+//
+// Parameters:
+//   - n: The maximum body size in bytes.
+func WithMaxBodySize(n int64) RequestOption {
+	return func(s *settings) {
+		s.maxBodySize = n
+	}
+}
+
+// GetJSON sends a GET request to url and decodes the JSON response.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//   - client: The client sending the request; `http.DefaultClient` if nil.
+//   - url: The URL to request.
+//   - opts: Options customizing the call.
+func GetJSON[T any](ctx context.Context, client *http.Client, url string, opts ...RequestOption) (nilo.Option[T], error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nilo.Nil[T](), err
+	}
+	return DoJSON[T](client, req, opts...)
+}
+
+// PostJSON sends body encoded as JSON in a POST request to url and decodes
+// the JSON response.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//   - client: The client sending the request; `http.DefaultClient` if nil.
+//   - url: The URL to request.
+//   - body: The value encoded as the request body.
+//   - opts: Options customizing the call.
+func PostJSON[T any](ctx context.Context, client *http.Client, url string, body any, opts ...RequestOption) (nilo.Option[T], error) {
+	return SendJSON[T](ctx, client, http.MethodPost, url, body, opts...)
+}
+
+// SendJSON sends body encoded as JSON in a request with the given method and
+// decodes the JSON response.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//   - client: The client sending the request; `http.DefaultClient` if nil.
+//   - method: The HTTP method, such as `http.MethodPut`.
+//   - url: The URL to request.
+//   - body: The value encoded as the request body.
+//   - opts: Options customizing the call.
+func SendJSON[T any](ctx context.Context, client *http.Client, method, url string, body any, opts ...RequestOption) (nilo.Option[T], error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nilo.Nil[T](), fmt.Errorf("httpc: encoding request body: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(data))
+	if err != nil {
+		return nilo.Nil[T](), err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return DoJSON[T](client, req, opts...)
+}
+
+// DoJSON sends req and decodes the JSON response according to its
+// `Outcome`. The response body is always closed.
+//
+// The headers of the options are set on a clone of req, so req can be sent
+// again.
+//
+// Parameters:
+//   - client: The client sending the request; `http.DefaultClient` if nil.
+//   - req: The request to send.
+//   - opts: Options customizing the call.
+func DoJSON[T any](client *http.Client, req *http.Request, opts ...RequestOption) (nilo.Option[T], error) {
+	s := settings{classify: DefaultClassifier, header: http.Header{}, maxBodySize: DefaultMaxBodySize}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req = req.Clone(req.Context())
+	for key, values := range s.header {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nilo.Nil[T](), err
+	}
+	defer resp.Body.Close()
+
+	switch s.classify(resp.StatusCode) {
+	case Absent:
+		// Draining lets the connection be reused, but only up to the body
+		// size limit so an endless body cannot hold the call.
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, s.maxBodySize))
+		return nilo.Nil[T](), nil
+	case Fail:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, s.maxBodySize))
+		return nilo.Nil[T](), &StatusError{req.Method, req.URL.Redacted(), resp.StatusCode, body}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, s.maxBodySize+1))
+	if err != nil {
+		return nilo.Nil[T](), fmt.Errorf("httpc: reading response body: %w", err)
+	}
+	if int64(len(data)) > s.maxBodySize {
+		return nilo.Nil[T](), fmt.Errorf("httpc: response body exceeds %d bytes", s.maxBodySize)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nilo.Nil[T](), nil
+	}
+
+	var result nilo.Option[T]
+	if err := result.UnmarshalJSON(data); err != nil {
+		return nilo.Nil[T](), fmt.Errorf("httpc: decoding response body: %w", err)
+	}
+	return result, nil
+}
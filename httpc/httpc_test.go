@@ -0,0 +1,212 @@
+package httpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/javiorfo/nilo"
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	Name  string              `json:"name"`
+	Email nilo.Option[string] `json:"email"`
+}
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Accept", r.Header.Get("Accept"))
+		_, _ = w.Write([]byte(`{"name":"Ana","email":"ana@mail.com"}`))
+	})
+	mux.HandleFunc("GET /users/2", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name":"Bob","email":null}`))
+	})
+	mux.HandleFunc("GET /users/null", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`null`))
+	})
+	mux.HandleFunc("GET /users/empty", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("GET /users/none", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /users/broken", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "database unavailable", http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("GET /users/bad", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name":`))
+	})
+	mux.HandleFunc("GET /auth", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`"` + r.Header.Get("Authorization") + `"`))
+	})
+	mux.HandleFunc("GET /headers", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(r.Header.Values("Authorization"))
+	})
+	mux.HandleFunc("GET /users/endless", func(w http.ResponseWriter, r *http.Request) {
+		status, _ := strconv.Atoi(r.URL.Query().Get("status"))
+		w.WriteHeader(status)
+		chunk := bytes.Repeat([]byte("x"), 1024)
+		for {
+			if _, err := w.Write(chunk); err != nil {
+				return
+			}
+		}
+	})
+	mux.HandleFunc("POST /users", func(w http.ResponseWriter, r *http.Request) {
+		var u user
+		if err := json.NewDecoder(r.Body).Decode(&u); err != nil || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(u)
+	})
+	mux.HandleFunc("PUT /users/1", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGetJSON(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+
+	t.Run("value", func(t *testing.T) {
+		result, err := GetJSON[user](ctx, srv.Client(), srv.URL+"/users/1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Ana", result.AsValue().Name)
+		assert.Equal(t, nilo.Value("ana@mail.com"), result.AsValue().Email)
+	})
+
+	t.Run("nested Nil", func(t *testing.T) {
+		result, err := GetJSON[user](ctx, srv.Client(), srv.URL+"/users/2")
+
+		assert.NoError(t, err)
+		assert.True(t, result.IsValue())
+		assert.True(t, result.AsValue().Email.IsNil())
+	})
+
+	t.Run("absent responses are Nil", func(t *testing.T) {
+		for _, path := range []string{"/users/missing", "/users/none", "/users/null", "/users/empty"} {
+			result, err := GetJSON[user](ctx, nil, srv.URL+path)
+
+			assert.NoError(t, err, path)
+			assert.True(t, result.IsNil(), path)
+		}
+	})
+
+	t.Run("failure status", func(t *testing.T) {
+		result, err := GetJSON[user](ctx, srv.Client(), srv.URL+"/users/broken")
+
+		var statusErr *StatusError
+		assert.True(t, errors.As(err, &statusErr))
+		assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode())
+		assert.Equal(t, "database unavailable\n", string(statusErr.Body))
+		assert.Contains(t, err.Error(), "GET")
+		assert.False(t, nilo.IsNotFound(err))
+		assert.True(t, result.IsNil())
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		_, err := GetJSON[user](ctx, srv.Client(), srv.URL+"/users/bad")
+
+		assert.ErrorContains(t, err, "decoding response body")
+	})
+
+	t.Run("custom classifier", func(t *testing.T) {
+		strict := func(status int) Outcome {
+			if status == http.StatusNotFound {
+				return Fail
+			}
+			return DefaultClassifier(status)
+		}
+		_, err := GetJSON[user](ctx, srv.Client(), srv.URL+"/users/missing", WithClassifier(strict))
+
+		assert.Error(t, err)
+		assert.True(t, nilo.IsNotFound(err))
+	})
+
+	t.Run("headers", func(t *testing.T) {
+		result, err := GetJSON[string](ctx, srv.Client(), srv.URL+"/auth", WithHeader("Authorization", "Bearer x"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, nilo.Value("Bearer x"), result)
+	})
+
+	t.Run("requests are reusable", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/headers", nil)
+		assert.NoError(t, err)
+
+		for range 2 {
+			result, err := DoJSON[[]string](srv.Client(), req, WithHeader("Authorization", "Bearer x"))
+
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"Bearer x"}, result.AsValue())
+		}
+		assert.Empty(t, req.Header)
+	})
+
+	t.Run("body size limit", func(t *testing.T) {
+		_, err := GetJSON[user](ctx, srv.Client(), srv.URL+"/users/1", WithMaxBodySize(8))
+		assert.EqualError(t, err, "httpc: response body exceeds 8 bytes")
+
+		result, err := GetJSON[user](ctx, srv.Client(), srv.URL+"/users/1", WithMaxBodySize(64))
+		assert.NoError(t, err)
+		assert.Equal(t, "Ana", result.AsValue().Name)
+	})
+
+	t.Run("endless bodies of absent and failed responses", func(t *testing.T) {
+		for _, status := range []int{http.StatusNotFound, http.StatusInternalServerError} {
+			timeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+			result, _ := GetJSON[user](timeout, srv.Client(), srv.URL+"/users/endless?status="+strconv.Itoa(status), WithMaxBodySize(1<<16))
+
+			assert.NoError(t, timeout.Err(), status)
+			assert.True(t, result.IsNil())
+			cancel()
+		}
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := GetJSON[user](canceled, srv.Client(), srv.URL+"/users/1")
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestSendJSON(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+
+	t.Run("post", func(t *testing.T) {
+		result, err := PostJSON[user](ctx, srv.Client(), srv.URL+"/users", user{Name: "Ana"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Ana", result.AsValue().Name)
+		assert.True(t, result.AsValue().Email.IsNil())
+	})
+
+	t.Run("put without content", func(t *testing.T) {
+		result, err := SendJSON[user](ctx, srv.Client(), http.MethodPut, srv.URL+"/users/1", user{Name: "Ana"})
+
+		assert.NoError(t, err)
+		assert.True(t, result.IsNil())
+	})
+
+	t.Run("unencodable body", func(t *testing.T) {
+		_, err := PostJSON[user](ctx, srv.Client(), srv.URL+"/users", make(chan int))
+
+		assert.ErrorContains(t, err, "encoding request body")
+	})
+}
@@ -2,8 +2,13 @@ package nilo
 
 import (
 	"bytes"
+	"encoding/gob"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
+	"reflect"
 )
 
 // MarshalJSON implements the `json.Marshaler` interface for `Option`.
@@ -47,3 +52,216 @@ func (o Option[T]) String() string {
 	}
 	return "Nil"
 }
+
+// Format implements the `fmt.Formatter` interface for `Option`.
+//
+// For a `Value` `Option`, the verb and its flags, width and precision are
+// applied to the contained value inside the "Value(...)" wrapper, so
+// `fmt.Sprintf("%q", Value("a"))` gives `Value("a")` and
+// `fmt.Sprintf("%5.1f", Value(2.0))` gives "Value(  2.0)". A `Nil` `Option`
+// is printed as "Nil". The `%#v` verb prints the result of `GoString`.
+func (o Option[T]) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && f.Flag('#'):
+		_, _ = io.WriteString(f, o.GoString())
+	case o.IsNil():
+		_, _ = io.WriteString(f, "Nil")
+	default:
+		_, _ = fmt.Fprintf(f, "Value("+fmt.FormatString(f, verb)+")", *o.value)
+	}
+}
+
+// GoString implements the `fmt.GoStringer` interface for `Option`.
+//
+// It returns the Go syntax creating the `Option`, such as
+// `nilo.Value[int](3)` or `nilo.Nil[string]()`, which keeps the output of
+// `%#v` and of test failure diffs readable.
+func (o Option[T]) GoString() string {
+	typ := reflect.TypeFor[T]().String()
+	if o.IsNil() {
+		return fmt.Sprintf("nilo.Nil[%s]()", typ)
+	}
+	return fmt.Sprintf("nilo.Value[%s](%#v)", typ, *o.value)
+}
+
+// GobEncode implements the `gob.GobEncoder` interface for `Option`.
+//
+// The encoding starts with a presence byte: `0` for a `Nil` `Option` and `1`
+// for a `Value` `Option`, which is followed by the gob encoding of the
+// wrapped value.
+func (o Option[T]) GobEncode() ([]byte, error) {
+	if o.IsNil() {
+		return []byte{0}, nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(1)
+	if err := gob.NewEncoder(&buf).Encode(o.value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode implements the `gob.GobDecoder` interface for `Option`.
+//
+// It reads the presence byte written by `GobEncode`, creating a `Nil`
+// `Option` for `0` and decoding the wrapped value for `1`.
+func (o *Option[T]) GobDecode(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("nilo: missing Option presence byte")
+	}
+
+	switch data[0] {
+	case 0:
+		o.value = nil
+		return nil
+	case 1:
+		var v T
+		if err := gob.NewDecoder(bytes.NewReader(data[1:])).Decode(&v); err != nil {
+			return err
+		}
+		o.value = &v
+		return nil
+	default:
+		return fmt.Errorf("nilo: invalid Option presence byte %d", data[0])
+	}
+}
+
+// xsiNamespace is the XML Schema instance namespace used by the `xsi:nil`
+// attribute.
+const xsiNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+
+// MarshalXML implements the `xml.Marshaler` interface for `Option`.
+//
+// If the `Option` is `Nil`, the element is omitted from the output.
+// If the `Option` is `Value`, the wrapped value is encoded using `start`
+// as its element. Use `XMLNillable` to emit `xsi:nil="true"` instead of
+// omitting the element.
+func (o Option[T]) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if o.IsNil() {
+		return nil
+	}
+	return e.EncodeElement(o.AsValue(), start)
+}
+
+// UnmarshalXML implements the `xml.Unmarshaler` interface for `Option`.
+//
+// If the element carries `xsi:nil="true"`, it unmarshals into a `Nil` `Option`.
+// Otherwise, it decodes the element into the `Option`'s value, creating a
+// `Value` `Option`. An absent element leaves the `Option` untouched, so
+// zero-valued fields stay `Nil`.
+func (o *Option[T]) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	if isXSINil(start) {
+		o.value = nil
+		return d.Skip()
+	}
+
+	var v T
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+
+	o.value = &v
+	return nil
+}
+
+// MarshalXMLAttr implements the `xml.MarshalerAttr` interface for `Option`.
+//
+// If the `Option` is `Nil`, the attribute is omitted. If the `Option` is
+// `Value`, the wrapped value is encoded with the same rules `encoding/xml`
+// applies to attribute fields.
+func (o Option[T]) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	if o.IsNil() {
+		return xml.Attr{}, nil
+	}
+
+	b, err := xml.Marshal(xmlAttrHolder[T]{V: o.AsValue()})
+	if err != nil {
+		return xml.Attr{}, err
+	}
+
+	tok, err := xml.NewDecoder(bytes.NewReader(b)).Token()
+	if err != nil {
+		return xml.Attr{}, err
+	}
+
+	for _, attr := range tok.(xml.StartElement).Attr {
+		if attr.Name.Local == "v" {
+			return xml.Attr{Name: name, Value: attr.Value}, nil
+		}
+	}
+	return xml.Attr{}, nil
+}
+
+// UnmarshalXMLAttr implements the `xml.UnmarshalerAttr` interface for `Option`.
+//
+// The attribute value is decoded with the same rules `encoding/xml` applies
+// to attribute fields, creating a `Value` `Option`. An absent attribute
+// leaves the `Option` untouched.
+func (o *Option[T]) UnmarshalXMLAttr(attr xml.Attr) error {
+	var buf bytes.Buffer
+	buf.WriteString(`<a v="`)
+	if err := xml.EscapeText(&buf, []byte(attr.Value)); err != nil {
+		return err
+	}
+	buf.WriteString(`"/>`)
+
+	var holder xmlAttrHolder[T]
+	if err := xml.Unmarshal(buf.Bytes(), &holder); err != nil {
+		return err
+	}
+
+	o.value = &holder.V
+	return nil
+}
+
+// XMLNillable wraps an `Option` so that a `Nil` value is marshaled as an
+// empty element carrying `xsi:nil="true"` instead of being omitted.
+//
+// Use it in place of `Option` on the fields whose schema declares them
+// `nillable`. Every other behaviour is inherited from the embedded `Option`.
+//
+// Example:
+//
+//	type Customer struct {
+//		Email nilo.XMLNillable[string] `xml:"email"`
+//	}
+type XMLNillable[T any] struct {
+	Option[T]
+}
+
+// MarshalXML implements the `xml.Marshaler` interface for `XMLNillable`.
+//
+// If the `Option` is `Nil`, it marshals to an empty element with
+// `xsi:nil="true"`. Otherwise, it behaves like `Option.MarshalXML`.
+func (n XMLNillable[T]) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if n.IsValue() {
+		return n.Option.MarshalXML(e, start)
+	}
+
+	start.Attr = append(start.Attr,
+		xml.Attr{Name: xml.Name{Local: "xmlns:xsi"}, Value: xsiNamespace},
+		xml.Attr{Name: xml.Name{Local: "xsi:nil"}, Value: "true"},
+	)
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+type xmlAttrHolder[T any] struct {
+	XMLName xml.Name `xml:"a"`
+	V       T        `xml:"v,attr"`
+}
+
+func isXSINil(start xml.StartElement) bool {
+	for _, attr := range start.Attr {
+		if attr.Name.Local != "nil" {
+			continue
+		}
+		if attr.Name.Space == xsiNamespace || attr.Name.Space == "xsi" {
+			return attr.Value == "true" || attr.Value == "1"
+		}
+	}
+	return false
+}
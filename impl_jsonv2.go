@@ -0,0 +1,49 @@
+//go:build goexperiment.jsonv2 && go1.27
+
+// Go 1.27 lists encoding/json/v2 as part of the standard library, so vet
+// requires files using it to be built for go1.27 or later. Earlier
+// toolchains shipping the package behind the experiment build
+// impl_jsonv2_pre127.go instead, which must be kept in sync with this file.
+
+package nilo
+
+import (
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+)
+
+// MarshalJSONTo implements the `json.MarshalerTo` interface of
+// `encoding/json/v2` for `Option`.
+//
+// It behaves like `MarshalJSON` but writes straight to the encoder,
+// avoiding the intermediate allocation. A `Nil` `Option` encodes as `null`,
+// so it is dropped by both the `omitzero` and the `omitempty` options.
+func (o Option[T]) MarshalJSONTo(enc *jsontext.Encoder) error {
+	if o.IsNil() {
+		return enc.WriteToken(jsontext.Null)
+	}
+	return json.MarshalEncode(enc, o.value)
+}
+
+// UnmarshalJSONFrom implements the `json.UnmarshalerFrom` interface of
+// `encoding/json/v2` for `Option`.
+//
+// It behaves like `UnmarshalJSON` but reads straight from the decoder.
+// If the next value is `null`, it unmarshals into a `Nil` `Option`.
+func (o *Option[T]) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	if dec.PeekKind() == 'n' {
+		if _, err := dec.ReadToken(); err != nil {
+			return err
+		}
+		o.value = nil
+		return nil
+	}
+
+	var v T
+	if err := json.UnmarshalDecode(dec, &v); err != nil {
+		return err
+	}
+
+	o.value = &v
+	return nil
+}
@@ -0,0 +1,47 @@
+//go:build goexperiment.jsonv2 && !go1.27
+
+// Go 1.25 and 1.26 ship encoding/json/v2 behind GOEXPERIMENT=jsonv2. This
+// file mirrors impl_jsonv2.go for them and must be kept in sync with it.
+
+package nilo
+
+import (
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+)
+
+// MarshalJSONTo implements the `json.MarshalerTo` interface of
+// `encoding/json/v2` for `Option`.
+//
+// It behaves like `MarshalJSON` but writes straight to the encoder,
+// avoiding the intermediate allocation. A `Nil` `Option` encodes as `null`,
+// so it is dropped by both the `omitzero` and the `omitempty` options.
+func (o Option[T]) MarshalJSONTo(enc *jsontext.Encoder) error {
+	if o.IsNil() {
+		return enc.WriteToken(jsontext.Null)
+	}
+	return json.MarshalEncode(enc, o.value)
+}
+
+// UnmarshalJSONFrom implements the `json.UnmarshalerFrom` interface of
+// `encoding/json/v2` for `Option`.
+//
+// It behaves like `UnmarshalJSON` but reads straight from the decoder.
+// If the next value is `null`, it unmarshals into a `Nil` `Option`.
+func (o *Option[T]) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	if dec.PeekKind() == 'n' {
+		if _, err := dec.ReadToken(); err != nil {
+			return err
+		}
+		o.value = nil
+		return nil
+	}
+
+	var v T
+	if err := json.UnmarshalDecode(dec, &v); err != nil {
+		return err
+	}
+
+	o.value = &v
+	return nil
+}
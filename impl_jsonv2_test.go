@@ -0,0 +1,141 @@
+//go:build goexperiment.jsonv2 && go1.27
+
+package nilo
+
+import (
+	jsonv1 "encoding/json"
+	"encoding/json/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type jsonv2User struct {
+	Name     Option[string]   `json:"name"`
+	Email    Option[string]   `json:"email,omitzero"`
+	Nickname Option[string]   `json:"nickname,omitempty"`
+	Tags     Option[[]string] `json:"tags"`
+}
+
+func TestImplJSONv2(t *testing.T) {
+	t.Run("Marshal", func(t *testing.T) {
+		t.Run("MarshalJSONTo on Value Options", func(t *testing.T) {
+			input := jsonv2User{
+				Name:     Value("John"),
+				Email:    Value("john@mail.com"),
+				Nickname: Value("jj"),
+				Tags:     Value([]string{"a"}),
+			}
+			expected := `{"name":"John","email":"john@mail.com","nickname":"jj","tags":["a"]}`
+
+			result, err := json.Marshal(input)
+
+			assert.NoError(t, err)
+			assert.Equal(t, expected, string(result))
+		})
+
+		t.Run("MarshalJSONTo on Nil Options honours omitzero and omitempty", func(t *testing.T) {
+			expected := `{"name":null,"tags":null}`
+
+			result, err := json.Marshal(jsonv2User{})
+
+			assert.NoError(t, err)
+			assert.Equal(t, expected, string(result))
+		})
+
+		t.Run("MarshalJSONTo on standalone Options", func(t *testing.T) {
+			cases := []struct {
+				input    any
+				expected string
+			}{
+				{Value([]string{"a", "b"}), `["a","b"]`},
+				{Nil[[]string](), `null`},
+				{Value(Value(3)), `3`},
+				{Value(Nil[int]()), `null`},
+				{Value(map[string]int{"b": 2, "a": 1}), `{"a":1,"b":2}`},
+			}
+			for _, c := range cases {
+				result, err := json.Marshal(c.input, json.Deterministic(true))
+				assert.NoError(t, err)
+				assert.Equal(t, c.expected, string(result))
+			}
+		})
+	})
+
+	t.Run("Unmarshal", func(t *testing.T) {
+		t.Run("UnmarshalJSONFrom from null and absent fields", func(t *testing.T) {
+			result := jsonv2User{Name: Value("previous")}
+
+			err := json.Unmarshal([]byte(`{"name":null}`), &result)
+
+			assert.NoError(t, err)
+			assert.True(t, result.Name.IsNil())
+			assert.True(t, result.Email.IsNil())
+		})
+
+		t.Run("UnmarshalJSONFrom from valid values", func(t *testing.T) {
+			var result jsonv2User
+
+			err := json.Unmarshal([]byte(`{"name":"John","tags":["a","b"]}`), &result)
+
+			assert.NoError(t, err)
+			assert.Equal(t, "John", result.Name.AsValue())
+			assert.Equal(t, []string{"a", "b"}, result.Tags.AsValue())
+		})
+
+		t.Run("UnmarshalJSONFrom with invalid data returns an error", func(t *testing.T) {
+			var result Option[int]
+
+			err := json.Unmarshal([]byte(`"not an int"`), &result)
+
+			assert.Error(t, err)
+			assert.True(t, result.IsNil())
+		})
+	})
+}
+
+func BenchmarkMarshalJSON(b *testing.B) {
+	input := jsonv2User{Name: Value("John"), Email: Value("john@mail.com"), Tags: Value([]string{"a", "b"})}
+
+	b.Run("v1", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, err := jsonv1.Marshal(input); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("v2", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, err := json.Marshal(input); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkUnmarshalJSON(b *testing.B) {
+	data := []byte(`{"name":"John","email":"john@mail.com","tags":["a","b"]}`)
+
+	b.Run("v1", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			var result jsonv2User
+			if err := jsonv1.Unmarshal(data, &result); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("v2", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			var result jsonv2User
+			if err := json.Unmarshal(data, &result); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
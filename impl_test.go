@@ -1,7 +1,11 @@
 package nilo
 
 import (
+	"bytes"
+	"encoding/gob"
 	"encoding/json"
+	"encoding/xml"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -81,4 +85,192 @@ func TestImpl(t *testing.T) {
 			assert.Equal(t, expected, result)
 		})
 	})
+
+	t.Run("Format", func(t *testing.T) {
+		type point struct{ X, Y int }
+
+		tests := []struct {
+			format   string
+			input    any
+			expected string
+		}{
+			{"%v", Value(10), "Value(10)"},
+			{"%s", Value("a"), "Value(a)"},
+			{"%q", Value("a"), `Value("a")`},
+			{"%5d", Value(42), "Value(   42)"},
+			{"%-4d|", Value(7), "Value(7   )|"},
+			{"%.2f", Value(3.14159), "Value(3.14)"},
+			{"%x", Value(255), "Value(ff)"},
+			{"%+v", Value(point{1, 2}), "Value({X:1 Y:2})"},
+			{"%v", Value(Value(1)), "Value(Value(1))"},
+			{"%v", Nil[int](), "Nil"},
+			{"%5d", Nil[int](), "Nil"},
+			{"%q", Nil[string](), "Nil"},
+		}
+
+		for _, tt := range tests {
+			assert.Equal(t, tt.expected, fmt.Sprintf(tt.format, tt.input), tt.format)
+		}
+	})
+
+	t.Run("GoString", func(t *testing.T) {
+		type point struct{ X, Y int }
+
+		tests := []struct {
+			input    any
+			expected string
+		}{
+			{Value(3), "nilo.Value[int](3)"},
+			{Value("a"), `nilo.Value[string]("a")`},
+			{Nil[string](), "nilo.Nil[string]()"},
+			{Value(Value(3)), "nilo.Value[nilo.Option[int]](nilo.Value[int](3))"},
+			{Value(point{1, 2}), "nilo.Value[nilo.point](nilo.point{X:1, Y:2})"},
+			{Value([]int{1}), "nilo.Value[[]int]([]int{1})"},
+		}
+
+		for _, tt := range tests {
+			assert.Equal(t, tt.expected, fmt.Sprintf("%#v", tt.input))
+		}
+		assert.Equal(t, "nilo.Value[int](3)", Value(3).GoString())
+	})
+
+	t.Run("XML", func(t *testing.T) {
+		type customer struct {
+			XMLName xml.Name            `xml:"customer"`
+			ID      Option[int]         `xml:"id,attr"`
+			Name    Option[string]      `xml:"name"`
+			Email   XMLNillable[string] `xml:"email"`
+		}
+
+		t.Run("MarshalXML on Value Options", func(t *testing.T) {
+			input := customer{
+				ID:    Value(7),
+				Name:  Value("John"),
+				Email: XMLNillable[string]{Value("john@mail.com")},
+			}
+			expected := `<customer id="7"><name>John</name><email>john@mail.com</email></customer>`
+
+			result, err := xml.Marshal(input)
+
+			assert.NoError(t, err)
+			assert.Equal(t, expected, string(result))
+		})
+
+		t.Run("MarshalXML on Nil Options", func(t *testing.T) {
+			input := customer{}
+			expected := `<customer><email xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:nil="true"></email></customer>`
+
+			result, err := xml.Marshal(input)
+
+			assert.NoError(t, err)
+			assert.Equal(t, expected, string(result))
+		})
+
+		t.Run("UnmarshalXML from present elements", func(t *testing.T) {
+			var result customer
+			data := `<customer id="7"><name>John</name><email>john@mail.com</email></customer>`
+
+			err := xml.Unmarshal([]byte(data), &result)
+
+			assert.NoError(t, err)
+			assert.Equal(t, 7, result.ID.AsValue())
+			assert.Equal(t, "John", result.Name.AsValue())
+			assert.Equal(t, "john@mail.com", result.Email.AsValue())
+		})
+
+		t.Run("UnmarshalXML from absent and xsi:nil elements", func(t *testing.T) {
+			var result customer
+			data := `<customer xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"><name xsi:nil="true"/></customer>`
+
+			err := xml.Unmarshal([]byte(data), &result)
+
+			assert.NoError(t, err)
+			assert.True(t, result.ID.IsNil())
+			assert.True(t, result.Name.IsNil())
+			assert.True(t, result.Email.IsNil())
+		})
+
+		t.Run("UnmarshalXML round trip of Nil Options", func(t *testing.T) {
+			var result customer
+			data, err := xml.Marshal(customer{})
+			assert.NoError(t, err)
+
+			err = xml.Unmarshal(data, &result)
+
+			assert.NoError(t, err)
+			assert.True(t, result.Email.IsNil())
+		})
+
+		t.Run("UnmarshalXMLAttr with invalid data returns an error", func(t *testing.T) {
+			var result customer
+			data := `<customer id="abc"></customer>`
+
+			err := xml.Unmarshal([]byte(data), &result)
+
+			assert.Error(t, err)
+			assert.True(t, result.ID.IsNil())
+		})
+	})
+
+	t.Run("Gob", func(t *testing.T) {
+		type address struct {
+			Street string
+			Number Option[int]
+		}
+		type cached struct {
+			Name    Option[string]
+			Address Option[address]
+			Nested  Option[Option[int]]
+			Scores  map[string]Option[int]
+		}
+
+		roundTrip := func(t *testing.T, input cached) cached {
+			var buf bytes.Buffer
+			var result cached
+
+			assert.NoError(t, gob.NewEncoder(&buf).Encode(input))
+			assert.NoError(t, gob.NewDecoder(&buf).Decode(&result))
+			return result
+		}
+
+		t.Run("GobEncode and GobDecode of Value Options", func(t *testing.T) {
+			input := cached{
+				Name:    Value("John"),
+				Address: Value(address{"Main", Value(10)}),
+				Nested:  Value(Value(0)),
+				Scores:  map[string]Option[int]{"a": Value(1), "b": Nil[int]()},
+			}
+
+			result := roundTrip(t, input)
+
+			assert.Equal(t, "John", result.Name.AsValue())
+			assert.Equal(t, "Main", result.Address.AsValue().Street)
+			assert.Equal(t, 10, result.Address.AsValue().Number.AsValue())
+			assert.Equal(t, 0, result.Nested.AsValue().AsValue())
+			assert.Equal(t, 1, result.Scores["a"].AsValue())
+			assert.True(t, result.Scores["b"].IsNil())
+		})
+
+		t.Run("GobEncode and GobDecode of Nil Options", func(t *testing.T) {
+			input := cached{
+				Address: Value(address{Street: "Main"}),
+				Nested:  Value(Nil[int]()),
+			}
+
+			result := roundTrip(t, input)
+
+			assert.True(t, result.Name.IsNil())
+			assert.True(t, result.Address.AsValue().Number.IsNil())
+			assert.True(t, result.Nested.IsValue())
+			assert.True(t, result.Nested.AsValue().IsNil())
+		})
+
+		t.Run("GobDecode with invalid data returns an error", func(t *testing.T) {
+			var opt Option[int]
+
+			assert.Error(t, opt.GobDecode(nil))
+			assert.Error(t, opt.GobDecode([]byte{2}))
+			assert.True(t, opt.IsNil())
+		})
+	})
 }
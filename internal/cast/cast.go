@@ -0,0 +1,229 @@
+// Package cast implements the conversion rules behind `nilo.Cast` for
+// values whose target type is only known at run time.
+package cast
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// To converts value to the type target.
+//
+// The rules are, in order:
+//  1. Values already assignable to target are returned as is.
+//  2. Any value converts to a string kind: numbers and booleans are
+//     formatted with `strconv`, byte and rune slices are converted as Go
+//     does, everything else is formatted with `fmt.Sprint`.
+//  3. Strings are parsed into integer, float and boolean kinds.
+//  4. Otherwise Go's conversion rules apply, except that numbers out of
+//     the range of the target kind, and NaN into integers, do not convert
+//     instead of wrapping.
+//
+// The boolean result reports whether the conversion succeeded; a nil value
+// never converts.
+func To(value any, target reflect.Type) (reflect.Value, bool) {
+	val := reflect.ValueOf(value)
+	if !val.IsValid() {
+		return reflect.Value{}, false
+	}
+	if val.Type().AssignableTo(target) {
+		return val.Convert(target), true
+	}
+
+	if target.Kind() == reflect.String {
+		return reflect.ValueOf(format(val, value)).Convert(target), true
+	}
+
+	if val.Kind() == reflect.String {
+		if v, ok := parse(val.String(), target); ok {
+			return v, true
+		}
+	}
+
+	if convertible(val, target) {
+		return val.Convert(target), true
+	}
+
+	return reflect.Value{}, false
+}
+
+// convertible reports whether val converts to target without panicking.
+// Go allows converting slices to arrays and array pointers, but only when
+// the slice is long enough.
+func convertible(val reflect.Value, target reflect.Type) bool {
+	if !val.Type().ConvertibleTo(target) {
+		return false
+	}
+	if isNumber(val.Kind()) && isNumber(target.Kind()) {
+		return fits(val, target)
+	}
+	if val.Kind() != reflect.Slice {
+		return true
+	}
+	switch target.Kind() {
+	case reflect.Array:
+		return val.Len() >= target.Len()
+	case reflect.Pointer:
+		return !val.IsNil() && val.Len() >= target.Elem().Len()
+	}
+	return true
+}
+
+func isNumber(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64
+}
+
+// fits reports whether the number val is in the range of target, after
+// truncating floats into integers. Floats are checked before converting,
+// since converting a float out of the range of an integer type is
+// implementation defined.
+func fits(val reflect.Value, target reflect.Type) bool {
+	dst := reflect.New(target).Elem()
+	switch {
+	case val.CanInt():
+		i := val.Int()
+		switch {
+		case dst.CanInt():
+			return !dst.OverflowInt(i)
+		case dst.CanUint():
+			return i >= 0 && !dst.OverflowUint(uint64(i))
+		}
+	case val.CanUint():
+		u := val.Uint()
+		switch {
+		case dst.CanInt():
+			return u <= math.MaxInt64 && !dst.OverflowInt(int64(u))
+		case dst.CanUint():
+			return !dst.OverflowUint(u)
+		}
+	default:
+		f := val.Float()
+		switch {
+		case math.IsNaN(f):
+			return dst.CanFloat()
+		case dst.CanInt():
+			// The float64 bounds are -2^63 and 2^63.
+			return f >= math.MinInt64 && f < math.MaxInt64 && !dst.OverflowInt(int64(f))
+		case dst.CanUint():
+			return f > -1 && f < math.MaxUint64 && !dst.OverflowUint(uint64(f))
+		case math.IsInf(f, 0):
+			return true
+		}
+		return !dst.OverflowFloat(f)
+	}
+	return true
+}
+
+func format(val reflect.Value, value any) string {
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(val.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(val.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(val.Float(), 'f', -1, val.Type().Bits())
+	case reflect.Bool:
+		return strconv.FormatBool(val.Bool())
+	case reflect.String:
+		return val.String()
+	case reflect.Slice:
+		if val.Type().ConvertibleTo(reflect.TypeFor[string]()) {
+			return val.Convert(reflect.TypeFor[string]()).String()
+		}
+		return fmt.Sprint(value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func parse(s string, target reflect.Type) (reflect.Value, bool) {
+	v := reflect.New(target).Elem()
+	switch target.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, target.Bits())
+		if err != nil {
+			return reflect.Value{}, false
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, target.Bits())
+		if err != nil {
+			return reflect.Value{}, false
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, target.Bits())
+		if err != nil {
+			return reflect.Value{}, false
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return reflect.Value{}, false
+		}
+		v.SetBool(b)
+	default:
+		return reflect.Value{}, false
+	}
+	return v, true
+}
+
+var (
+	textMarshaler   = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshaler = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// IsText reports whether t implements `encoding.TextUnmarshaler` through
+// its pointer, so values of t should be parsed as a whole.
+func IsText(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(textUnmarshaler)
+}
+
+// FromText converts s to the type t. Types implementing
+// `encoding.TextUnmarshaler` parse s themselves, durations are parsed with
+// `time.ParseDuration` and the rest follow To.
+func FromText(s string, t reflect.Type) (reflect.Value, error) {
+	if t == reflect.TypeFor[time.Duration]() {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(d), nil
+	}
+
+	if IsText(t) {
+		v := reflect.New(t)
+		if err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return reflect.Value{}, err
+		}
+		return v.Elem(), nil
+	}
+
+	v, ok := To(s, t)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("cannot convert %q to %s", s, t)
+	}
+	return v, nil
+}
+
+// ToText formats v as a string. Types implementing `encoding.TextMarshaler`
+// format themselves, durations use their `String` method and the rest
+// follow To.
+func ToText(v reflect.Value) (string, error) {
+	if v.Type() == reflect.TypeFor[time.Duration]() {
+		return v.Interface().(time.Duration).String(), nil
+	}
+
+	if v.Type().Implements(textMarshaler) {
+		b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+
+	s, _ := To(v.Interface(), reflect.TypeFor[string]())
+	return s.String(), nil
+}
@@ -0,0 +1,157 @@
+package cast
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type name string
+
+func TestTo(t *testing.T) {
+	t.Run("assignable values", func(t *testing.T) {
+		v, ok := To(3, reflect.TypeFor[int]())
+		assert.True(t, ok)
+		assert.Equal(t, 3, v.Interface())
+	})
+
+	t.Run("to string kinds", func(t *testing.T) {
+		cases := []struct {
+			input    any
+			expected any
+			target   reflect.Type
+		}{
+			{-4, "-4", reflect.TypeFor[string]()},
+			{uint8(4), "4", reflect.TypeFor[string]()},
+			{1.5, "1.5", reflect.TypeFor[string]()},
+			{true, "true", reflect.TypeFor[string]()},
+			{"john", name("john"), reflect.TypeFor[name]()},
+			{[]int{1}, "[1]", reflect.TypeFor[string]()},
+			{[]byte("abc"), "abc", reflect.TypeFor[string]()},
+			{[]rune("é"), name("é"), reflect.TypeFor[name]()},
+		}
+		for _, c := range cases {
+			v, ok := To(c.input, c.target)
+			assert.True(t, ok)
+			assert.Equal(t, c.expected, v.Interface())
+		}
+	})
+
+	t.Run("from strings", func(t *testing.T) {
+		cases := []struct {
+			input    string
+			expected any
+		}{
+			{"-4", int8(-4)},
+			{"4", uint16(4)},
+			{"1.5", float32(1.5)},
+			{"true", true},
+		}
+		for _, c := range cases {
+			v, ok := To(c.input, reflect.TypeOf(c.expected))
+			assert.True(t, ok)
+			assert.Equal(t, c.expected, v.Interface())
+		}
+	})
+
+	t.Run("invalid and overflowing strings fail", func(t *testing.T) {
+		_, ok := To("abc", reflect.TypeFor[int]())
+		assert.False(t, ok)
+
+		_, ok = To("300", reflect.TypeFor[int8]())
+		assert.False(t, ok)
+	})
+
+	t.Run("Go conversions", func(t *testing.T) {
+		v, ok := To(3.9, reflect.TypeFor[int]())
+		assert.True(t, ok)
+		assert.Equal(t, 3, v.Interface())
+
+		_, ok = To(nil, reflect.TypeFor[int]())
+		assert.False(t, ok)
+
+		_, ok = To(nil, reflect.TypeFor[string]())
+		assert.False(t, ok)
+	})
+
+	t.Run("numbers out of range fail", func(t *testing.T) {
+		cases := []struct {
+			input  any
+			target reflect.Type
+		}{
+			{300, reflect.TypeFor[int8]()},
+			{-1, reflect.TypeFor[uint]()},
+			{uint64(math.MaxUint64), reflect.TypeFor[int64]()},
+			{math.NaN(), reflect.TypeFor[int]()},
+			{1e30, reflect.TypeFor[int]()},
+			{-1.0, reflect.TypeFor[uint8]()},
+			{1e39, reflect.TypeFor[float32]()},
+		}
+		for _, c := range cases {
+			_, ok := To(c.input, c.target)
+			assert.False(t, ok, "%v to %s", c.input, c.target)
+		}
+
+		v, ok := To(-0.5, reflect.TypeFor[uint]())
+		assert.True(t, ok)
+		assert.Equal(t, uint(0), v.Interface())
+
+		v, ok = To(math.Inf(-1), reflect.TypeFor[float32]())
+		assert.True(t, ok)
+		assert.Equal(t, float32(math.Inf(-1)), v.Interface())
+	})
+
+	t.Run("short slices do not convert to arrays", func(t *testing.T) {
+		v, ok := To([]byte{1, 2}, reflect.TypeFor[[2]byte]())
+		assert.True(t, ok)
+		assert.Equal(t, [2]byte{1, 2}, v.Interface())
+
+		_, ok = To([]byte{1}, reflect.TypeFor[[2]byte]())
+		assert.False(t, ok)
+
+		_, ok = To([]byte{1}, reflect.TypeFor[*[2]byte]())
+		assert.False(t, ok)
+
+		_, ok = To([]byte(nil), reflect.TypeFor[*[0]byte]())
+		assert.False(t, ok)
+	})
+}
+
+func TestText(t *testing.T) {
+	t.Run("FromText", func(t *testing.T) {
+		v, err := FromText("2024-01-02T03:04:05Z", reflect.TypeFor[time.Time]())
+		assert.NoError(t, err)
+		assert.Equal(t, 2024, v.Interface().(time.Time).Year())
+
+		v, err = FromText("7", reflect.TypeFor[int]())
+		assert.NoError(t, err)
+		assert.Equal(t, 7, v.Interface())
+
+		_, err = FromText("x", reflect.TypeFor[int]())
+		assert.EqualError(t, err, `cannot convert "x" to int`)
+
+		_, err = FromText("x", reflect.TypeFor[time.Time]())
+		assert.Error(t, err)
+
+		v, err = FromText("1m30s", reflect.TypeFor[time.Duration]())
+		assert.NoError(t, err)
+		assert.Equal(t, 90*time.Second, v.Interface())
+	})
+
+	t.Run("ToText", func(t *testing.T) {
+		s, err := ToText(reflect.ValueOf(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+		assert.NoError(t, err)
+		assert.Equal(t, "2024-01-02T03:04:05Z", s)
+
+		s, err = ToText(reflect.ValueOf(90 * time.Second))
+		assert.NoError(t, err)
+		assert.Equal(t, "1m30s", s)
+
+		s, err = ToText(reflect.ValueOf(1.5))
+		assert.NoError(t, err)
+		assert.Equal(t, "1.5", s)
+	})
+}
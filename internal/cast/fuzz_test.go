@@ -0,0 +1,52 @@
+package cast
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// textTypes are the targets checked by FuzzText.
+var textTypes = []reflect.Type{
+	reflect.TypeFor[int](), reflect.TypeFor[int8](), reflect.TypeFor[uint16](),
+	reflect.TypeFor[uint64](), reflect.TypeFor[float32](), reflect.TypeFor[float64](),
+	reflect.TypeFor[bool](), reflect.TypeFor[string](), reflect.TypeFor[name](),
+	reflect.TypeFor[time.Duration](), reflect.TypeFor[time.Time](), reflect.TypeFor[net.IP](),
+	reflect.TypeFor[[]byte](), reflect.TypeFor[[]string](), reflect.TypeFor[struct{}](),
+}
+
+func FuzzText(f *testing.F) {
+	for _, seed := range []string{
+		"", "0", "-1", "255", "1e3", "NaN", "-Inf", "true", "f", "1h30m", "-0s",
+		"2024-02-29T12:00:00Z", "2024-02-29T12:00:00.5+01:00", "10.0.0.1", "::1", "abc",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		for _, typ := range textTypes {
+			v, err := FromText(s, typ)
+			if err != nil {
+				continue
+			}
+			if v.Type() != typ {
+				t.Fatalf("FromText(%q, %s) returned a %s", s, typ, v.Type())
+			}
+
+			// The text of a parsed value is stable: it parses back to a
+			// value with the same text.
+			text, err := ToText(v)
+			if err != nil {
+				t.Fatalf("ToText(%#v): %v", v, err)
+			}
+			again, err := FromText(text, typ)
+			if err != nil {
+				t.Fatalf("FromText(%q, %s) of ToText(FromText(%q)): %v", text, typ, s, err)
+			}
+			if retext, _ := ToText(again); retext != text {
+				t.Fatalf("unstable %s text for %q: %q != %q", typ, s, text, retext)
+			}
+		}
+	})
+}
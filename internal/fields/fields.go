@@ -0,0 +1,130 @@
+// Package fields resolves the struct fields and tag options used by the
+// reflection-based nilo sub-packages.
+package fields
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Field describes an exported struct field as seen through a struct tag.
+type Field struct {
+	// Name is the tag name, or the Go field name when the tag has none.
+	Name string
+	// GoName is the Go field name.
+	GoName string
+	// Index is the index sequence for `reflect.Value.FieldByIndex`.
+	Index []int
+	// Type is the field's type.
+	Type reflect.Type
+	// Tagged reports whether the field carried the tag at all.
+	Tagged bool
+	// Tag is the field's full struct tag, to read companion tags.
+	Tag reflect.StructTag
+
+	opts []string
+}
+
+// Has reports whether the tag lists the option opt, e.g. `omitempty`.
+func (f Field) Has(opt string) bool {
+	for _, o := range f.opts {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup returns the value of a `key=value` tag option.
+func (f Field) Lookup(key string) (string, bool) {
+	for _, o := range f.opts {
+		if k, v, ok := strings.Cut(o, "="); ok && k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+type cacheKey struct {
+	t   reflect.Type
+	tag string
+}
+
+var cache sync.Map
+
+// Of returns the fields of the struct type t as described by the struct tag
+// named tag.
+//
+// Unexported fields, fields tagged `-` and embedded pointers, along with the
+// fields promoted through them, are skipped. Like `encoding/json`, fields of
+// embedded structs without a tag name are promoted, while an embedded struct
+// with a tag name is a single field. Results are cached per type and tag.
+func Of(t reflect.Type, tag string) []Field {
+	key := cacheKey{t, tag}
+	if fs, ok := cache.Load(key); ok {
+		return fs.([]Field)
+	}
+
+	var fs []Field
+	for _, sf := range reflect.VisibleFields(t) {
+		if !sf.IsExported() || hidden(t, sf.Index, tag) {
+			continue
+		}
+
+		value, tagged := sf.Tag.Lookup(tag)
+		if value == "-" {
+			continue
+		}
+
+		name, rest, _ := strings.Cut(value, ",")
+		if sf.Anonymous && name == "" && isStruct(sf.Type) {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		f := Field{Name: name, GoName: sf.Name, Index: sf.Index, Type: sf.Type, Tagged: tagged, Tag: sf.Tag}
+		if rest != "" {
+			f.opts = strings.Split(rest, ",")
+		}
+		fs = append(fs, f)
+	}
+
+	cache.Store(key, fs)
+	return fs
+}
+
+// ByName returns the field named name, if any.
+func ByName(fs []Field, name string) (Field, bool) {
+	for _, f := range fs {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+func isStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// hidden reports whether the field at index is promoted through an embedded
+// pointer, or through an embedded struct that is skipped or has a tag name.
+func hidden(t reflect.Type, index []int, tag string) bool {
+	for _, i := range index[:len(index)-1] {
+		sf := t.Field(i)
+		if name, _, _ := strings.Cut(sf.Tag.Get(tag), ","); name != "" {
+			return true
+		}
+		t = sf.Type
+		if t.Kind() == reflect.Pointer {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package fields
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Base struct {
+	ID int `test:"id"`
+}
+
+type Extra struct {
+	Note string
+}
+
+type sample struct {
+	Base
+	*Extra
+	Name    string `test:"name,omitempty,default=john"`
+	Skipped string `test:"-"`
+	Plain   int
+	private int
+}
+
+func TestFields(t *testing.T) {
+	fs := Of(reflect.TypeFor[sample](), "test")
+
+	t.Run("Of", func(t *testing.T) {
+		names := make([]string, len(fs))
+		for i, f := range fs {
+			names[i] = f.Name
+		}
+		assert.Equal(t, []string{"id", "name", "Plain"}, names)
+		assert.Equal(t, []int{0, 0}, fs[0].Index)
+		assert.True(t, fs[1].Tagged)
+		assert.False(t, fs[2].Tagged)
+	})
+
+	t.Run("Has and Lookup", func(t *testing.T) {
+		f, ok := ByName(fs, "name")
+		assert.True(t, ok)
+		assert.True(t, f.Has("omitempty"))
+		assert.False(t, f.Has("required"))
+
+		def, ok := f.Lookup("default")
+		assert.True(t, ok)
+		assert.Equal(t, "john", def)
+	})
+
+	t.Run("ByName", func(t *testing.T) {
+		_, ok := ByName(fs, "missing")
+		assert.False(t, ok)
+	})
+
+	t.Run("tagged embedded structs are not flattened", func(t *testing.T) {
+		type tagged struct {
+			Base  `test:"base"`
+			Extra `test:"-"`
+			Name  string `test:"name"`
+		}
+
+		fs := Of(reflect.TypeFor[tagged](), "test")
+		assert.Len(t, fs, 2)
+		assert.Equal(t, "base", fs[0].Name)
+		assert.Equal(t, reflect.TypeFor[Base](), fs[0].Type)
+		assert.Equal(t, "name", fs[1].Name)
+	})
+}
@@ -0,0 +1,67 @@
+// Package optreflect provides reflection helpers shared by the nilo codec
+// sub-packages to inspect and fill `nilo.Option` values without knowing
+// their type parameter at compile time.
+package optreflect
+
+import (
+	"reflect"
+	"strings"
+)
+
+const pkgPath = "github.com/javiorfo/nilo"
+
+// Is reports whether t is an instantiation of `nilo.Option`.
+func Is(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.PkgPath() == pkgPath && strings.HasPrefix(t.Name(), "Option[")
+}
+
+// Elem returns the type parameter `T` of the `nilo.Option[T]` type t.
+//
+// It panics if t is not an `Option`.
+func Elem(t reflect.Type) reflect.Type {
+	m, ok := t.MethodByName("AsPtr")
+	if !ok || !Is(t) {
+		panic("optreflect: Elem of non-Option type " + t.String())
+	}
+	return m.Type.Out(0).Elem()
+}
+
+// Get returns the value contained by the `Option` v and whether it is `Value`.
+//
+// The returned value is addressable when the `Option` is `Value`.
+func Get(v reflect.Value) (reflect.Value, bool) {
+	ptr := v.MethodByName("AsPtr").Call(nil)[0]
+	if ptr.IsNil() {
+		return reflect.Value{}, false
+	}
+	return ptr.Elem(), true
+}
+
+// Set stores elem in the addressable `Option` v, turning it into `Value`.
+func Set(v reflect.Value, elem reflect.Value) {
+	v.Addr().MethodByName("Insert").Call([]reflect.Value{elem})
+}
+
+// Clear turns the settable `Option` v into `Nil`.
+func Clear(v reflect.Value) {
+	v.Set(reflect.Zero(v.Type()))
+}
+
+// IsEmpty reports whether v is empty in the sense of the `omitempty` tag
+// option: a `Nil` `Option`, a nil pointer or interface, an empty array,
+// map, slice or string, or the zero value of any other kind.
+func IsEmpty(v reflect.Value) bool {
+	if Is(v.Type()) {
+		_, ok := Get(v)
+		return !ok
+	}
+
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	default:
+		return v.IsZero()
+	}
+}
@@ -0,0 +1,49 @@
+package optreflect
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/javiorfo/nilo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptReflect(t *testing.T) {
+	t.Run("Is", func(t *testing.T) {
+		assert.True(t, Is(reflect.TypeFor[nilo.Option[int]]()))
+		assert.True(t, Is(reflect.TypeFor[nilo.Option[nilo.Option[string]]]()))
+		assert.False(t, Is(reflect.TypeFor[int]()))
+		assert.False(t, Is(reflect.TypeFor[*nilo.Option[int]]()))
+	})
+
+	t.Run("Elem", func(t *testing.T) {
+		assert.Equal(t, reflect.TypeFor[[]string](), Elem(reflect.TypeFor[nilo.Option[[]string]]()))
+		assert.Panics(t, func() { Elem(reflect.TypeFor[string]()) })
+	})
+
+	t.Run("Get, Set and Clear", func(t *testing.T) {
+		opt := nilo.Nil[int]()
+		v := reflect.ValueOf(&opt).Elem()
+
+		_, ok := Get(v)
+		assert.False(t, ok)
+
+		Set(v, reflect.ValueOf(5))
+		got, ok := Get(v)
+		assert.True(t, ok)
+		assert.Equal(t, 5, got.Interface())
+		assert.Equal(t, 5, opt.AsValue())
+
+		Clear(v)
+		assert.True(t, opt.IsNil())
+	})
+
+	t.Run("IsEmpty", func(t *testing.T) {
+		assert.True(t, IsEmpty(reflect.ValueOf(nilo.Nil[int]())))
+		assert.False(t, IsEmpty(reflect.ValueOf(nilo.Value(0))))
+		assert.True(t, IsEmpty(reflect.ValueOf("")))
+		assert.True(t, IsEmpty(reflect.ValueOf([0]int{})))
+		assert.True(t, IsEmpty(reflect.ValueOf((*int)(nil))))
+		assert.False(t, IsEmpty(reflect.ValueOf(struct{ A int }{1})))
+	})
+}
@@ -0,0 +1,605 @@
+package msgpack
+
+import (
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/javiorfo/nilo/internal/fields"
+	"github.com/javiorfo/nilo/internal/optreflect"
+)
+
+// maxDepth bounds the nesting of arrays and maps to protect against
+// malicious input.
+const maxDepth = 10000
+
+type decoder struct {
+	data  []byte
+	off   int
+	depth int
+}
+
+func (d *decoder) decode(v reflect.Value) error {
+	b, err := d.peek()
+	if err != nil {
+		return err
+	}
+
+	if b == mpNil {
+		d.off++
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	t := v.Type()
+	if optreflect.Is(t) {
+		inner := reflect.New(optreflect.Elem(t)).Elem()
+		if err := d.decode(inner); err != nil {
+			return err
+		}
+		optreflect.Set(v, inner)
+		return nil
+	}
+
+	if ok, err := d.decodeUnmarshaler(v, b); ok {
+		return err
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		return d.decode(v.Elem())
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return &UnsupportedTypeError{t}
+		}
+		x, err := d.decodeAny()
+		if err != nil {
+			return err
+		}
+		if x == nil {
+			v.Set(reflect.Zero(t))
+		} else {
+			v.Set(reflect.ValueOf(x))
+		}
+		return nil
+	case reflect.Bool:
+		switch b {
+		case mpTrue, mpFalse:
+			d.off++
+			v.SetBool(b == mpTrue)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if isInteger(b) {
+			start := d.off
+			i, u, neg, err := d.readInteger()
+			if err != nil {
+				return err
+			}
+			if !neg {
+				if u > math.MaxInt64 {
+					return d.typeError("integer", t, start)
+				}
+				i = int64(u)
+			}
+			if v.OverflowInt(i) {
+				return d.typeError("integer", t, start)
+			}
+			v.SetInt(i)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if isInteger(b) {
+			start := d.off
+			_, u, neg, err := d.readInteger()
+			if err != nil {
+				return err
+			}
+			if neg || v.OverflowUint(u) {
+				return d.typeError("integer", t, start)
+			}
+			v.SetUint(u)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		if isInteger(b) || b == mpFloat32 || b == mpFloat64 {
+			x, err := d.decodeAny()
+			if err != nil {
+				return err
+			}
+			v.SetFloat(toFloat(x))
+			return nil
+		}
+	case reflect.String:
+		if isString(b) || isBin(b) {
+			s, err := d.readBytes()
+			if err != nil {
+				return err
+			}
+			v.SetString(string(s))
+			return nil
+		}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 && (isString(b) || isBin(b)) {
+			s, err := d.readBytes()
+			if err != nil {
+				return err
+			}
+			v.SetBytes(append([]byte{}, s...))
+			return nil
+		}
+		if isArray(b) {
+			n, err := d.readLen(1)
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.MakeSlice(t, n, n))
+			return d.decodeElems(v, n)
+		}
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && (isString(b) || isBin(b)) {
+			s, err := d.readBytes()
+			if err != nil {
+				return err
+			}
+			v.SetZero()
+			reflect.Copy(v, reflect.ValueOf(s))
+			return nil
+		}
+		if isArray(b) {
+			n, err := d.readLen(1)
+			if err != nil {
+				return err
+			}
+			v.SetZero()
+			return d.decodeElems(v, n)
+		}
+	case reflect.Map:
+		if isMap(b) {
+			return d.decodeMap(v)
+		}
+	case reflect.Struct:
+		if isMap(b) {
+			return d.decodeStruct(v)
+		}
+	default:
+		return &UnsupportedTypeError{t}
+	}
+
+	return d.typeError(describe(b), t, d.off)
+}
+
+// decodeUnmarshaler decodes a string with `encoding.TextUnmarshaler` and
+// binary data with `encoding.BinaryUnmarshaler`, or else
+// `encoding.TextUnmarshaler`, when v implements them, and reports whether
+// it did.
+func (d *decoder) decodeUnmarshaler(v reflect.Value, b byte) (bool, error) {
+	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface || !v.CanAddr() || !isString(b) && !isBin(b) {
+		return false, nil
+	}
+	text, isText := v.Addr().Interface().(encoding.TextUnmarshaler)
+	bin, isBinary := v.Addr().Interface().(encoding.BinaryUnmarshaler)
+	if !isText && (!isBinary || isString(b)) {
+		return false, nil
+	}
+
+	start := d.off
+	data, err := d.readBytes()
+	if err != nil {
+		return true, err
+	}
+	if isBinary && isBin(b) {
+		err = bin.UnmarshalBinary(append([]byte{}, data...))
+	} else {
+		err = text.UnmarshalText(append([]byte{}, data...))
+	}
+	if err != nil {
+		return true, fmt.Errorf("msgpack: decoding %s at offset %d: %w", v.Type(), start, err)
+	}
+	return true, nil
+}
+
+// decodeElems decodes n array elements into v, discarding those that do not
+// fit into a Go array.
+func (d *decoder) decodeElems(v reflect.Value, n int) error {
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+
+	for i := range n {
+		if i >= v.Len() {
+			if _, err := d.decodeAny(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := d.decode(v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *decoder) decodeMap(v reflect.Value) error {
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+
+	n, err := d.readLen(2)
+	if err != nil {
+		return err
+	}
+
+	t := v.Type()
+	if v.IsNil() {
+		v.Set(reflect.MakeMapWithSize(t, n))
+	}
+	for range n {
+		start := d.off
+		key := reflect.New(t.Key()).Elem()
+		if err := d.decode(key); err != nil {
+			return err
+		}
+		if !key.Comparable() {
+			return d.typeError("unhashable map key", t, start)
+		}
+		elem := reflect.New(t.Elem()).Elem()
+		if err := d.decode(elem); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
+	}
+	return nil
+}
+
+func (d *decoder) decodeStruct(v reflect.Value) error {
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+
+	n, err := d.readLen(2)
+	if err != nil {
+		return err
+	}
+
+	fs := fields.Of(v.Type(), tagName)
+	for range n {
+		b, err := d.peek()
+		if err != nil {
+			return err
+		}
+		if !isString(b) {
+			return d.typeError(describe(b), reflect.TypeFor[string](), d.off)
+		}
+		name, err := d.readBytes()
+		if err != nil {
+			return err
+		}
+
+		f, ok := fields.ByName(fs, string(name))
+		if !ok {
+			if _, err := d.decodeAny(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := d.decode(v.FieldByIndex(f.Index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeAny decodes the next value into its default Go representation.
+func (d *decoder) decodeAny() (any, error) {
+	b, err := d.peek()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case b == mpNil:
+		d.off++
+		return nil, nil
+	case b == mpTrue || b == mpFalse:
+		d.off++
+		return b == mpTrue, nil
+	case isInteger(b):
+		i, u, neg, err := d.readInteger()
+		if err != nil {
+			return nil, err
+		}
+		if neg {
+			return i, nil
+		}
+		if u > math.MaxInt64 {
+			return u, nil
+		}
+		return int64(u), nil
+	case b == mpFloat32:
+		raw, err := d.read(5)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(raw[1:])), nil
+	case b == mpFloat64:
+		raw, err := d.read(9)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(raw[1:])), nil
+	case isString(b):
+		s, err := d.readBytes()
+		return string(s), err
+	case isBin(b):
+		s, err := d.readBytes()
+		return append([]byte{}, s...), err
+	case isArray(b):
+		var x []any
+		err := d.decode(reflect.ValueOf(&x).Elem())
+		return x, err
+	case isMap(b):
+		return d.decodeAnyMap()
+	default:
+		return nil, fmt.Errorf("msgpack: unsupported format byte 0x%02x at offset %d", b, d.off)
+	}
+}
+
+func (d *decoder) decodeAnyMap() (any, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer d.leave()
+
+	n, err := d.readLen(2)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]any, n)
+	values := make([]any, n)
+	stringKeys := true
+	for i := range n {
+		start := d.off
+		if keys[i], err = d.decodeAny(); err != nil {
+			return nil, err
+		}
+		switch keys[i].(type) {
+		case string:
+		case []any, map[string]any, map[any]any, []byte:
+			return nil, d.typeError("unhashable map key", reflect.TypeFor[map[any]any](), start)
+		default:
+			stringKeys = false
+		}
+		if values[i], err = d.decodeAny(); err != nil {
+			return nil, err
+		}
+	}
+
+	if stringKeys {
+		m := make(map[string]any, n)
+		for i, k := range keys {
+			m[k.(string)] = values[i]
+		}
+		return m, nil
+	}
+	m := make(map[any]any, n)
+	for i, k := range keys {
+		m[k] = values[i]
+	}
+	return m, nil
+}
+
+// readInteger reads any integer format. Negative values are returned in i
+// with neg set, the others in u.
+func (d *decoder) readInteger() (i int64, u uint64, neg bool, err error) {
+	b := d.data[d.off]
+	switch {
+	case b < 0x80:
+		d.off++
+		return 0, uint64(b), false, nil
+	case b >= 0xe0:
+		d.off++
+		return int64(int8(b)), 0, true, nil
+	}
+
+	size := 1 << ((b - mpUint8) & 0x03)
+	raw, err := d.read(1 + size)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	raw = raw[1:]
+
+	var x uint64
+	switch size {
+	case 1:
+		x = uint64(raw[0])
+	case 2:
+		x = uint64(binary.BigEndian.Uint16(raw))
+	case 4:
+		x = uint64(binary.BigEndian.Uint32(raw))
+	default:
+		x = binary.BigEndian.Uint64(raw)
+	}
+
+	if b <= mpUint64 {
+		return 0, x, false, nil
+	}
+
+	switch size {
+	case 1:
+		i = int64(int8(x))
+	case 2:
+		i = int64(int16(x))
+	case 4:
+		i = int64(int32(x))
+	default:
+		i = int64(x)
+	}
+	if i >= 0 {
+		return 0, uint64(i), false, nil
+	}
+	return i, 0, true, nil
+}
+
+// readBytes reads the payload of a string or binary value.
+func (d *decoder) readBytes() ([]byte, error) {
+	b := d.data[d.off]
+	var n int
+	if b&0xe0 == mpFixStr {
+		d.off++
+		n = int(b & 0x1f)
+	} else {
+		var size int
+		switch b {
+		case mpStr8, mpBin8:
+			size = 1
+		case mpStr16, mpBin16:
+			size = 2
+		default:
+			size = 4
+		}
+		var err error
+		if n, err = d.readSize(size); err != nil {
+			return nil, err
+		}
+	}
+	return d.read(n)
+}
+
+// readLen reads the header of an array or a map. minSize is the minimum
+// number of bytes each entry takes, used to reject impossible lengths.
+func (d *decoder) readLen(minSize int) (int, error) {
+	b := d.data[d.off]
+	var n int
+	switch b {
+	case mpArray16, mpMap16:
+		var err error
+		if n, err = d.readSize(2); err != nil {
+			return 0, err
+		}
+	case mpArray32, mpMap32:
+		var err error
+		if n, err = d.readSize(4); err != nil {
+			return 0, err
+		}
+	default:
+		d.off++
+		n = int(b & 0x0f)
+	}
+
+	if n > (len(d.data)-d.off)/minSize {
+		return 0, ErrUnexpectedEnd
+	}
+	return n, nil
+}
+
+// readSize skips the format byte and reads a big-endian length of size bytes.
+func (d *decoder) readSize(size int) (int, error) {
+	raw, err := d.read(1 + size)
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return int(raw[1]), nil
+	case 2:
+		return int(binary.BigEndian.Uint16(raw[1:])), nil
+	default:
+		return int(binary.BigEndian.Uint32(raw[1:])), nil
+	}
+}
+
+func (d *decoder) read(n int) ([]byte, error) {
+	if n > len(d.data)-d.off {
+		return nil, ErrUnexpectedEnd
+	}
+	b := d.data[d.off : d.off+n]
+	d.off += n
+	return b, nil
+}
+
+func (d *decoder) peek() (byte, error) {
+	if d.off >= len(d.data) {
+		return 0, ErrUnexpectedEnd
+	}
+	return d.data[d.off], nil
+}
+
+func (d *decoder) enter() error {
+	d.depth++
+	if d.depth > maxDepth {
+		return fmt.Errorf("msgpack: exceeded max depth of %d", maxDepth)
+	}
+	return nil
+}
+
+func (d *decoder) leave() {
+	d.depth--
+}
+
+func (d *decoder) typeError(value string, t reflect.Type, offset int) error {
+	return &UnmarshalTypeError{Value: value, Type: t, Offset: offset}
+}
+
+func isInteger(b byte) bool {
+	return b < 0x80 || b >= 0xe0 || (b >= mpUint8 && b <= mpInt64)
+}
+
+func isString(b byte) bool {
+	return b&0xe0 == mpFixStr || b == mpStr8 || b == mpStr16 || b == mpStr32
+}
+
+func isBin(b byte) bool {
+	return b == mpBin8 || b == mpBin16 || b == mpBin32
+}
+
+func isArray(b byte) bool {
+	return b&0xf0 == mpFixArray || b == mpArray16 || b == mpArray32
+}
+
+func isMap(b byte) bool {
+	return b&0xf0 == mpFixMap || b == mpMap16 || b == mpMap32
+}
+
+func toFloat(x any) float64 {
+	switch n := x.(type) {
+	case int64:
+		return float64(n)
+	case uint64:
+		return float64(n)
+	case float32:
+		return float64(n)
+	default:
+		return n.(float64)
+	}
+}
+
+func describe(b byte) string {
+	switch {
+	case b == mpTrue || b == mpFalse:
+		return "bool"
+	case isInteger(b):
+		return "integer"
+	case b == mpFloat32 || b == mpFloat64:
+		return "float"
+	case isString(b):
+		return "string"
+	case isBin(b):
+		return "binary"
+	case isArray(b):
+		return "array"
+	case isMap(b):
+		return "map"
+	default:
+		return fmt.Sprintf("format 0x%02x", b)
+	}
+}
@@ -0,0 +1,308 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+
+	"github.com/javiorfo/nilo/internal/fields"
+	"github.com/javiorfo/nilo/internal/optreflect"
+)
+
+var (
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	binaryMarshalerType = reflect.TypeFor[encoding.BinaryMarshaler]()
+)
+
+type encoder struct {
+	buf   []byte
+	depth int
+}
+
+func (e *encoder) encode(v reflect.Value) error {
+	if !v.IsValid() {
+		e.buf = append(e.buf, mpNil)
+		return nil
+	}
+
+	if err := e.enter(); err != nil {
+		return err
+	}
+	defer e.leave()
+
+	if optreflect.Is(v.Type()) {
+		inner, ok := optreflect.Get(v)
+		if !ok {
+			e.buf = append(e.buf, mpNil)
+			return nil
+		}
+		return e.encode(inner)
+	}
+
+	if ok, err := e.encodeMarshaler(v); ok {
+		return err
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf = append(e.buf, mpTrue)
+		} else {
+			e.buf = append(e.buf, mpFalse)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.writeInt(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.writeUint(v.Uint())
+	case reflect.Float32:
+		e.buf = append(e.buf, mpFloat32)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		e.buf = append(e.buf, mpFloat64)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, math.Float64bits(v.Float()))
+	case reflect.String:
+		e.writeString(v.String())
+	case reflect.Slice:
+		if v.IsNil() {
+			e.buf = append(e.buf, mpNil)
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			e.writeBin(v.Bytes())
+			return nil
+		}
+		return e.encodeArray(v)
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			e.writeBin(b)
+			return nil
+		}
+		return e.encodeArray(v)
+	case reflect.Map:
+		if v.IsNil() {
+			e.buf = append(e.buf, mpNil)
+			return nil
+		}
+		return e.encodeMap(v)
+	case reflect.Struct:
+		return e.encodeStruct(v)
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			e.buf = append(e.buf, mpNil)
+			return nil
+		}
+		return e.encode(v.Elem())
+	default:
+		return &UnsupportedTypeError{v.Type()}
+	}
+	return nil
+}
+
+func (e *encoder) encodeArray(v reflect.Value) error {
+	e.writeHeader(v.Len(), mpFixArray, 16, mpArray16, mpArray32)
+	for i := range v.Len() {
+		if err := e.encode(v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *encoder) encodeMap(v reflect.Value) error {
+	type entry struct{ key, value []byte }
+
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		ke, ve := encoder{depth: e.depth}, encoder{depth: e.depth}
+		if err := ke.encode(iter.Key()); err != nil {
+			return err
+		}
+		if err := ve.encode(iter.Value()); err != nil {
+			return err
+		}
+		entries = append(entries, entry{ke.buf, ve.buf})
+	}
+	slices.SortFunc(entries, func(a, b entry) int { return bytes.Compare(a.key, b.key) })
+
+	e.writeHeader(len(entries), mpFixMap, 16, mpMap16, mpMap32)
+	for _, en := range entries {
+		e.buf = append(e.buf, en.key...)
+		e.buf = append(e.buf, en.value...)
+	}
+	return nil
+}
+
+// encodeMarshaler writes v as a string when it implements
+// `encoding.TextMarshaler`, or else as binary data when it implements
+// `encoding.BinaryMarshaler`, and reports whether it did.
+func (e *encoder) encodeMarshaler(v reflect.Value) (bool, error) {
+	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		return false, nil
+	}
+	if !v.Type().Implements(textMarshalerType) && !v.Type().Implements(binaryMarshalerType) {
+		if !v.CanAddr() {
+			return false, nil
+		}
+		v = v.Addr()
+	}
+
+	switch m := v.Interface().(type) {
+	case encoding.TextMarshaler:
+		text, err := m.MarshalText()
+		if err != nil {
+			return true, err
+		}
+		e.writeString(string(text))
+		return true, nil
+	case encoding.BinaryMarshaler:
+		data, err := m.MarshalBinary()
+		if err != nil {
+			return true, err
+		}
+		e.writeBin(data)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (e *encoder) encodeStruct(v reflect.Value) error {
+	fs := fields.Of(v.Type(), tagName)
+	if len(fs) == 0 && !exported(v.Type()) {
+		return &UnsupportedTypeError{v.Type()}
+	}
+
+	present := make([]fields.Field, 0, len(fs))
+	for _, f := range fs {
+		if f.Has("omitempty") && optreflect.IsEmpty(v.FieldByIndex(f.Index)) {
+			continue
+		}
+		present = append(present, f)
+	}
+
+	e.writeHeader(len(present), mpFixMap, 16, mpMap16, mpMap32)
+	for _, f := range present {
+		e.writeString(f.Name)
+		if err := e.encode(v.FieldByIndex(f.Index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exported reports whether t has no fields or an exported one. Encoding a
+// struct whose data is all unexported, like a `time.Time` without its
+// marshaler, as an empty map would lose it silently.
+func exported(t reflect.Type) bool {
+	for i := range t.NumField() {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return t.NumField() == 0
+}
+
+// enter bounds the nesting of values, which is endless for pointer
+// cycles.
+func (e *encoder) enter() error {
+	e.depth++
+	if e.depth > maxDepth {
+		return fmt.Errorf("msgpack: exceeded max depth of %d", maxDepth)
+	}
+	return nil
+}
+
+func (e *encoder) leave() {
+	e.depth--
+}
+
+func (e *encoder) writeInt(i int64) {
+	switch {
+	case i >= 0:
+		e.writeUint(uint64(i))
+	case i >= -32:
+		e.buf = append(e.buf, byte(i))
+	case i >= math.MinInt8:
+		e.buf = append(e.buf, mpInt8, byte(i))
+	case i >= math.MinInt16:
+		e.buf = append(e.buf, mpInt16)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(i))
+	case i >= math.MinInt32:
+		e.buf = append(e.buf, mpInt32)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(i))
+	default:
+		e.buf = append(e.buf, mpInt64)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(i))
+	}
+}
+
+func (e *encoder) writeUint(u uint64) {
+	switch {
+	case u < 128:
+		e.buf = append(e.buf, byte(u))
+	case u <= math.MaxUint8:
+		e.buf = append(e.buf, mpUint8, byte(u))
+	case u <= math.MaxUint16:
+		e.buf = append(e.buf, mpUint16)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(u))
+	case u <= math.MaxUint32:
+		e.buf = append(e.buf, mpUint32)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(u))
+	default:
+		e.buf = append(e.buf, mpUint64)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, u)
+	}
+}
+
+func (e *encoder) writeString(s string) {
+	n := len(s)
+	switch {
+	case n < 32:
+		e.buf = append(e.buf, mpFixStr|byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, mpStr8, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, mpStr16)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, mpStr32)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	}
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) writeBin(b []byte) {
+	n := len(b)
+	switch {
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, mpBin8, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, mpBin16)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, mpBin32)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	}
+	e.buf = append(e.buf, b...)
+}
+
+// writeHeader writes the length header of an array or a map, using the
+// fix format when n is below fixLimit.
+func (e *encoder) writeHeader(n int, fix byte, fixLimit int, f16, f32 byte) {
+	switch {
+	case n < fixLimit:
+		e.buf = append(e.buf, fix|byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, f16)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, f32)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	}
+}
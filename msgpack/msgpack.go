@@ -0,0 +1,126 @@
+// Package msgpack implements a MessagePack encoder and decoder with
+// first-class support for `nilo.Option`. It is built on reflection and the
+// standard library only.
+//
+// A `Nil` `Option` is encoded as the MessagePack `nil` value and a `Value`
+// `Option` as its wrapped value. Structs are encoded as maps keyed by field
+// name, which can be customized with the `msgpack` struct tag:
+//
+//	type User struct {
+//		Name  string              `msgpack:"name"`
+//		Email nilo.Option[string] `msgpack:"email,omitempty"`
+//		Token string              `msgpack:"-"`
+//	}
+//
+// Fields tagged `omitempty` are left out when they hold their zero value or
+// a `Nil` `Option`, so the receiver can tell an absent field from an explicit
+// `nil`. Absent fields are left untouched by `Unmarshal`, which keeps fresh
+// `Option` fields `Nil`.
+//
+// Values implementing `encoding.TextMarshaler` are encoded as strings, and
+// else those implementing `encoding.BinaryMarshaler` as binary data, and
+// decoded with their unmarshaler, so a `time.Time` round trips in RFC 3339.
+// Structs with only unexported fields
+// and no marshaler cannot be encoded.
+package msgpack
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+const tagName = "msgpack"
+
+// Format bytes from the MessagePack specification.
+const (
+	mpNil      byte = 0xc0
+	mpFalse    byte = 0xc2
+	mpTrue     byte = 0xc3
+	mpBin8     byte = 0xc4
+	mpBin16    byte = 0xc5
+	mpBin32    byte = 0xc6
+	mpFloat32  byte = 0xca
+	mpFloat64  byte = 0xcb
+	mpUint8    byte = 0xcc
+	mpUint16   byte = 0xcd
+	mpUint32   byte = 0xce
+	mpUint64   byte = 0xcf
+	mpInt8     byte = 0xd0
+	mpInt16    byte = 0xd1
+	mpInt32    byte = 0xd2
+	mpInt64    byte = 0xd3
+	mpStr8     byte = 0xd9
+	mpStr16    byte = 0xda
+	mpStr32    byte = 0xdb
+	mpArray16  byte = 0xdc
+	mpArray32  byte = 0xdd
+	mpMap16    byte = 0xde
+	mpMap32    byte = 0xdf
+	mpFixMap   byte = 0x80
+	mpFixArray byte = 0x90
+	mpFixStr   byte = 0xa0
+)
+
+// ErrUnexpectedEnd is returned when the input ends in the middle of a value.
+var ErrUnexpectedEnd = errors.New("msgpack: unexpected end of input")
+
+// UnsupportedTypeError is returned by `Marshal` and `Unmarshal` when a Go
+// type cannot be represented in MessagePack.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return "msgpack: unsupported type " + e.Type.String()
+}
+
+// UnmarshalTypeError describes a MessagePack value that could not be stored
+// in a Go value of a specific type.
+type UnmarshalTypeError struct {
+	// Value is a description of the MessagePack value, e.g. "string".
+	Value string
+	// Type is the Go type it could not be assigned to.
+	Type reflect.Type
+	// Offset is the position in the input where the value starts.
+	Offset int
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	return fmt.Sprintf("msgpack: cannot unmarshal %s into Go value of type %s at offset %d", e.Value, e.Type, e.Offset)
+}
+
+// Marshal returns the MessagePack encoding of v.
+//
+// Map keys are written in the order of their encoded bytes, so equal values
+// always produce equal output.
+func Marshal(v any) ([]byte, error) {
+	var e encoder
+	if err := e.encode(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+// Unmarshal parses the MessagePack-encoded data and stores the result in
+// the value pointed to by v, which must be a non-nil pointer.
+//
+// A MessagePack `nil` turns `Option`s `Nil` and other values into their zero
+// value. Into an `any`, values decode as `nil`, `bool`, `int64`, `uint64`,
+// `float32`, `float64`, `string`, `[]byte`, `[]any` and `map[string]any`
+// (or `map[any]any` for non-string keys).
+func Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("msgpack: Unmarshal requires a non-nil pointer")
+	}
+
+	d := decoder{data: data}
+	if err := d.decode(rv.Elem()); err != nil {
+		return err
+	}
+	if d.off != len(d.data) {
+		return fmt.Errorf("msgpack: %d bytes of trailing data", len(d.data)-d.off)
+	}
+	return nil
+}
@@ -0,0 +1,288 @@
+package msgpack
+
+import (
+	"encoding/json"
+	"math"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/javiorfo/nilo"
+	"github.com/stretchr/testify/assert"
+)
+
+type address struct {
+	Street string           `msgpack:"street" json:"street"`
+	Number nilo.Option[int] `msgpack:"number" json:"number"`
+}
+
+type user struct {
+	Name    string                `msgpack:"name" json:"name"`
+	Email   nilo.Option[string]   `msgpack:"email,omitempty" json:"email"`
+	Age     nilo.Option[int64]    `msgpack:"age" json:"age"`
+	Score   nilo.Option[float64]  `msgpack:"score" json:"score"`
+	Tags    nilo.Option[[]string] `msgpack:"tags" json:"tags"`
+	Address nilo.Option[address]  `msgpack:"address" json:"address"`
+	Secret  string                `msgpack:"-" json:"-"`
+}
+
+func TestMsgpack(t *testing.T) {
+	t.Run("Marshal", func(t *testing.T) {
+		t.Run("Nil Option encodes as nil", func(t *testing.T) {
+			result, err := Marshal(nilo.Nil[int]())
+
+			assert.NoError(t, err)
+			assert.Equal(t, []byte{0xc0}, result)
+		})
+
+		t.Run("Value Option encodes its value", func(t *testing.T) {
+			result, err := Marshal(nilo.Value(1))
+
+			assert.NoError(t, err)
+			assert.Equal(t, []byte{0x01}, result)
+		})
+
+		t.Run("omitempty leaves Nil fields absent", func(t *testing.T) {
+			result, err := Marshal(struct {
+				A nilo.Option[int] `msgpack:"a,omitempty"`
+				B nilo.Option[int] `msgpack:"b"`
+			}{})
+
+			assert.NoError(t, err)
+			assert.Equal(t, []byte{0x81, 0xa1, 'b', 0xc0}, result)
+		})
+
+		t.Run("scalars use the smallest format", func(t *testing.T) {
+			cases := []struct {
+				input    any
+				expected []byte
+			}{
+				{-1, []byte{0xff}},
+				{-33, []byte{0xd0, 0xdf}},
+				{200, []byte{0xcc, 0xc8}},
+				{uint16(1000), []byte{0xcd, 0x03, 0xe8}},
+				{true, []byte{0xc3}},
+				{"hi", []byte{0xa2, 'h', 'i'}},
+				{[]byte{1}, []byte{0xc4, 0x01, 0x01}},
+				{[]int{1, 2}, []byte{0x92, 0x01, 0x02}},
+			}
+			for _, c := range cases {
+				result, err := Marshal(c.input)
+				assert.NoError(t, err)
+				assert.Equal(t, c.expected, result, "%v", c.input)
+			}
+		})
+
+		t.Run("unsupported types return an error", func(t *testing.T) {
+			_, err := Marshal(make(chan int))
+
+			var unsupported *UnsupportedTypeError
+			assert.ErrorAs(t, err, &unsupported)
+
+			type secret struct{ token string }
+			_, err = Marshal(nilo.Value(secret{"x"}))
+			assert.ErrorAs(t, err, &unsupported)
+		})
+
+		t.Run("pointer cycles return an error", func(t *testing.T) {
+			type node struct {
+				Next *node
+			}
+			n := &node{}
+			n.Next = n
+
+			_, err := Marshal(n)
+
+			assert.ErrorContains(t, err, "exceeded max depth")
+		})
+	})
+
+	t.Run("Unmarshal", func(t *testing.T) {
+		t.Run("round trip of Value Options", func(t *testing.T) {
+			input := user{
+				Name:    "John",
+				Email:   nilo.Value("john@mail.com"),
+				Age:     nilo.Value(int64(-40)),
+				Score:   nilo.Value(9.5),
+				Tags:    nilo.Value([]string{"a", "b"}),
+				Address: nilo.Value(address{"Main", nilo.Value(10)}),
+				Secret:  "hidden",
+			}
+			var result user
+
+			data, err := Marshal(input)
+			assert.NoError(t, err)
+			err = Unmarshal(data, &result)
+
+			assert.NoError(t, err)
+			input.Secret = ""
+			assert.Equal(t, input, result)
+		})
+
+		t.Run("round trip of marshalers", func(t *testing.T) {
+			type event struct {
+				At      time.Time              `msgpack:"at"`
+				Updated nilo.Option[time.Time] `msgpack:"updated"`
+				Addr    netip.Addr             `msgpack:"addr"`
+			}
+			at := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+			input := event{at, nilo.Value(at.Add(time.Hour)), netip.MustParseAddr("10.0.0.1")}
+			var result event
+
+			data, err := Marshal(input)
+			assert.NoError(t, err)
+			err = Unmarshal(data, &result)
+
+			assert.NoError(t, err)
+			assert.Equal(t, input, result)
+		})
+
+		t.Run("nil and absent fields become Nil", func(t *testing.T) {
+			var result user
+
+			data, err := Marshal(user{Name: "John"})
+			assert.NoError(t, err)
+			err = Unmarshal(data, &result)
+
+			assert.NoError(t, err)
+			assert.Equal(t, "John", result.Name)
+			assert.True(t, result.Email.IsNil())
+			assert.True(t, result.Age.IsNil())
+			assert.True(t, result.Address.IsNil())
+		})
+
+		t.Run("nil resets a Value Option", func(t *testing.T) {
+			result := nilo.Value(3)
+
+			err := Unmarshal([]byte{0xc0}, &result)
+
+			assert.NoError(t, err)
+			assert.True(t, result.IsNil())
+		})
+
+		t.Run("into any", func(t *testing.T) {
+			var result any
+
+			data, err := Marshal(map[string]any{"a": 1, "b": []any{"x", nil}})
+			assert.NoError(t, err)
+			err = Unmarshal(data, &result)
+
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]any{"a": int64(1), "b": []any{"x", nil}}, result)
+		})
+
+		t.Run("type mismatch returns an error", func(t *testing.T) {
+			var result nilo.Option[int]
+
+			err := Unmarshal([]byte{0xa1, 'x'}, &result)
+
+			var typeErr *UnmarshalTypeError
+			assert.ErrorAs(t, err, &typeErr)
+			assert.True(t, result.IsNil())
+		})
+
+		t.Run("unhashable map keys return an error", func(t *testing.T) {
+			var result map[any]any
+
+			err := Unmarshal([]byte{0x81, 0x90, 0xc0}, &result)
+
+			var typeErr *UnmarshalTypeError
+			assert.ErrorAs(t, err, &typeErr)
+		})
+
+		t.Run("overflow returns an error", func(t *testing.T) {
+			var result int8
+
+			err := Unmarshal([]byte{0xcc, 0xc8}, &result)
+
+			assert.Error(t, err)
+		})
+
+		t.Run("truncated input returns an error", func(t *testing.T) {
+			var result []string
+
+			err := Unmarshal([]byte{0xdd, 0xff, 0xff, 0xff, 0xff}, &result)
+
+			assert.ErrorIs(t, err, ErrUnexpectedEnd)
+		})
+
+		t.Run("non-pointer target returns an error", func(t *testing.T) {
+			assert.Error(t, Unmarshal([]byte{0xc0}, user{}))
+		})
+	})
+}
+
+// FuzzRoundTrip checks that a value survives a MessagePack round trip with
+// the same JSON representation as the `Option` JSON codec gives it.
+func FuzzRoundTrip(f *testing.F) {
+	f.Add("John", true, int64(30), true, 1.5, true, "tag", true)
+	f.Add("", false, int64(0), false, 0.0, false, "", false)
+	f.Add("\xff", true, int64(math.MinInt64), true, -0.0, true, "a\x00b", true)
+
+	f.Fuzz(func(t *testing.T, name string, hasName bool, age int64, hasAge bool, score float64, hasScore bool, tag string, hasTags bool) {
+		if math.IsNaN(score) || math.IsInf(score, 0) {
+			t.Skip()
+		}
+
+		input := user{Name: name, Email: nilo.Nil[string]()}
+		if hasName {
+			input.Email = nilo.Value(name)
+		}
+		if hasAge {
+			input.Age = nilo.Value(age)
+		}
+		if hasScore {
+			input.Score = nilo.Value(score)
+		}
+		if hasTags {
+			input.Tags = nilo.Value([]string{tag, name})
+			input.Address = nilo.Value(address{tag, input.Age.MapToInt(func(i int64) int { return int(i % 1000) })})
+		}
+
+		data, err := Marshal(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fromMsgpack user
+		if err := Unmarshal(data, &fromMsgpack); err != nil {
+			t.Fatal(err)
+		}
+
+		expected, err := json.Marshal(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fromJSON user
+		if err := json.Unmarshal(expected, &fromJSON); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := json.Marshal(fromMsgpack)
+		if err != nil {
+			t.Fatal(err)
+		}
+		viaJSON, err := json.Marshal(fromJSON)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, string(expected), string(got))
+		assert.Equal(t, string(viaJSON), string(got))
+	})
+}
+
+// FuzzUnmarshal checks that arbitrary input never makes the decoder panic.
+func FuzzUnmarshal(f *testing.F) {
+	f.Add([]byte{0xc0})
+	f.Add([]byte{0x82, 0xa4, 'n', 'a', 'm', 'e', 0xa1, 'x', 0xa3, 'a', 'g', 'e', 0x05})
+	f.Add([]byte{0xdf, 0x00, 0x00, 0x00, 0x01})
+	f.Add([]byte{0x81, 0x90, 0xc0})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var u user
+		_ = Unmarshal(data, &u)
+		var x any
+		_ = Unmarshal(data, &x)
+		var m map[any]any
+		_ = Unmarshal(data, &m)
+	})
+}
@@ -0,0 +1,75 @@
+package nilotest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/javiorfo/nilo"
+)
+
+// RequireValue returns the contained value of o, stopping the test with
+// `t.Fatalf` if o is `Nil`.
+//
+// Parameters:
+//   - t: The test reporting failures.
+//   - o: The `Option` expected to be `Value`.
+func RequireValue[T any](t testing.TB, o nilo.Option[T]) T {
+	t.Helper()
+	if o.IsNil() {
+		t.Fatalf("expected a Value %s, got %#v", reflect.TypeFor[T](), o)
+		var zero T
+		return zero
+	}
+	return o.AsValue()
+}
+
+// AssertNil reports a failure with `t.Errorf` if o is `Value`, and returns
+// whether o is `Nil`.
+//
+// Parameters:
+//   - t: The test reporting failures.
+//   - o: The `Option` expected to be `Nil`.
+func AssertNil[T any](t testing.TB, o nilo.Option[T]) bool {
+	t.Helper()
+	if o.IsValue() {
+		t.Errorf("expected Nil, got %#v", o)
+		return false
+	}
+	return true
+}
+
+// AssertValueEqual reports a failure with `t.Errorf` unless o is `Value`
+// and its value is deeply equal to expected, and returns whether it is.
+//
+// Parameters:
+//   - t: The test reporting failures.
+//   - o: The `Option` to check.
+//   - expected: The value o is expected to contain.
+func AssertValueEqual[T any](t testing.TB, o nilo.Option[T], expected T) bool {
+	t.Helper()
+	if o.IsValue() && reflect.DeepEqual(o.AsValue(), expected) {
+		return true
+	}
+	t.Errorf("Option not equal:\nexpected: %#v\n  actual: %#v", nilo.Value(expected), o)
+	return false
+}
+
+// AssertValueMatches reports a failure with `t.Errorf` unless o is `Value`
+// and its value satisfies predicate, and returns whether it does.
+//
+// Parameters:
+//   - t: The test reporting failures.
+//   - o: The `Option` to check.
+//   - predicate: The function the contained value must satisfy.
+func AssertValueMatches[T any](t testing.TB, o nilo.Option[T], predicate func(T) bool) bool {
+	t.Helper()
+	switch {
+	case o.IsNil():
+		t.Errorf("expected a Value %s matching the predicate, got %#v", reflect.TypeFor[T](), o)
+		return false
+	case !predicate(o.AsValue()):
+		t.Errorf("value does not match the predicate: %#v", o)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,71 @@
+package nilotest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/javiorfo/nilo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireValue(t *testing.T) {
+	t.Run("when Value", func(t *testing.T) {
+		r := &recorder{TB: t}
+
+		assert.Equal(t, 3, RequireValue(r, nilo.Value(3)))
+		assert.Empty(t, r.failures)
+	})
+
+	t.Run("when Nil", func(t *testing.T) {
+		r := &recorder{TB: t}
+
+		assert.Equal(t, "", RequireValue(r, nilo.Nil[string]()))
+		assert.Equal(t, []string{"expected a Value string, got nilo.Nil[string]()"}, r.failures)
+	})
+}
+
+func TestAssertNil(t *testing.T) {
+	r := &recorder{TB: t}
+
+	assert.True(t, AssertNil(r, nilo.Nil[int]()))
+	assert.False(t, AssertNil(r, nilo.Value(7)))
+	assert.Equal(t, []string{"expected Nil, got nilo.Value[int](7)"}, r.failures)
+}
+
+func TestAssertValueEqual(t *testing.T) {
+	t.Run("when equal", func(t *testing.T) {
+		r := &recorder{TB: t}
+
+		assert.True(t, AssertValueEqual(r, nilo.Value([]string{"a"}), []string{"a"}))
+		assert.True(t, AssertValueEqual(r, nilo.Value(account{ID: 1, Email: nilo.Value("a@b.c")}), account{ID: 1, Email: nilo.Value("a@b.c")}))
+		assert.Empty(t, r.failures)
+	})
+
+	t.Run("when different", func(t *testing.T) {
+		r := &recorder{TB: t}
+
+		assert.False(t, AssertValueEqual(r, nilo.Value(2), 3))
+		assert.Equal(t, []string{"Option not equal:\nexpected: nilo.Value[int](3)\n  actual: nilo.Value[int](2)"}, r.failures)
+	})
+
+	t.Run("when Nil", func(t *testing.T) {
+		r := &recorder{TB: t}
+
+		assert.False(t, AssertValueEqual(r, nilo.Nil[string](), "x"))
+		assert.Equal(t, []string{"Option not equal:\nexpected: nilo.Value[string](\"x\")\n  actual: nilo.Nil[string]()"}, r.failures)
+	})
+}
+
+func TestAssertValueMatches(t *testing.T) {
+	hasAt := func(s string) bool { return strings.Contains(s, "@") }
+
+	r := &recorder{TB: t}
+
+	assert.True(t, AssertValueMatches(r, nilo.Value("a@b.c"), hasAt))
+	assert.False(t, AssertValueMatches(r, nilo.Value("abc"), hasAt))
+	assert.False(t, AssertValueMatches(r, nilo.Nil[string](), hasAt))
+	assert.Equal(t, []string{
+		`value does not match the predicate: nilo.Value[string]("abc")`,
+		"expected a Value string matching the predicate, got nilo.Nil[string]()",
+	}, r.failures)
+}
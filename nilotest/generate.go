@@ -0,0 +1,165 @@
+package nilotest
+
+import (
+	"math/rand"
+	"reflect"
+	"testing/quick"
+
+	"github.com/javiorfo/nilo"
+	"github.com/javiorfo/nilo/internal/optreflect"
+)
+
+// DefaultNilRatio is the share of `Nil` `Option`s produced by a `Generator`
+// without a `NilRatio`.
+const DefaultNilRatio = 0.25
+
+// complexSize bounds the length of generated slices and maps, as
+// `testing/quick` does.
+const complexSize = 50
+
+// Generator produces arbitrary values like `quick.Value`, including
+// `nilo.Option`s and the types holding them, which `quick.Value` cannot
+// fill since their fields are unexported.
+type Generator struct {
+	// NilRatio is the probability of generating a `Nil` `Option`;
+	// `DefaultNilRatio` when `Nil`.
+	NilRatio nilo.Option[float64]
+}
+
+// Value returns an arbitrary value of type t and whether t can be
+// generated.
+//
+// An `Option` is `Nil` with the probability given by `NilRatio` and
+// otherwise holds an arbitrary value. `Option`s of types that cannot be
+// generated, such as channels or functions, are always `Nil`.
+//
+// Parameters:
+//   - t: The type of the value.
+//   - rand: The source of randomness.
+func (g Generator) Value(t reflect.Type, rand *rand.Rand) (reflect.Value, bool) {
+	if !holdsOption(t, nil) {
+		return quick.Value(t, rand)
+	}
+
+	v := reflect.New(t).Elem()
+	switch {
+	case optreflect.Is(t):
+		if rand.Float64() < g.NilRatio.Or(DefaultNilRatio) {
+			return v, true
+		}
+		if elem, ok := g.Value(optreflect.Elem(t), rand); ok {
+			optreflect.Set(v, elem)
+		}
+	case t.Kind() == reflect.Pointer:
+		if rand.Intn(complexSize) == 0 {
+			return v, true
+		}
+		elem, ok := g.Value(t.Elem(), rand)
+		if !ok {
+			return reflect.Value{}, false
+		}
+		v.Set(reflect.New(t.Elem()))
+		v.Elem().Set(elem)
+	case t.Kind() == reflect.Slice:
+		n := rand.Intn(complexSize)
+		v.Set(reflect.MakeSlice(t, n, n))
+		return v, g.fill(v, n, rand)
+	case t.Kind() == reflect.Array:
+		return v, g.fill(v, t.Len(), rand)
+	case t.Kind() == reflect.Map:
+		v.Set(reflect.MakeMap(t))
+		for range rand.Intn(complexSize) {
+			key, ok := g.Value(t.Key(), rand)
+			if !ok {
+				return reflect.Value{}, false
+			}
+			elem, ok := g.Value(t.Elem(), rand)
+			if !ok {
+				return reflect.Value{}, false
+			}
+			v.SetMapIndex(key, elem)
+		}
+	case t.Kind() == reflect.Struct:
+		for i := range t.NumField() {
+			if !t.Field(i).IsExported() {
+				return reflect.Value{}, false
+			}
+			field, ok := g.Value(t.Field(i).Type, rand)
+			if !ok {
+				return reflect.Value{}, false
+			}
+			v.Field(i).Set(field)
+		}
+	}
+	return v, true
+}
+
+// Config returns a copy of config, or of the defaults if nil, whose
+// `Values` generate the arguments of property with `Value`, so
+// `quick.Check` and `quick.CheckEqual` accept functions taking `Option`s.
+// A config that already sets `Values` is copied as is.
+//
+// Parameters:
+//   - property: The function whose arguments are generated.
+//   - config: The `testing/quick` configuration to copy.
+func (g Generator) Config(property any, config *quick.Config) *quick.Config {
+	var c quick.Config
+	if config != nil {
+		c = *config
+	}
+	ft := reflect.TypeOf(property)
+	if c.Values != nil || ft == nil || ft.Kind() != reflect.Func {
+		return &c
+	}
+
+	c.Values = func(args []reflect.Value, rand *rand.Rand) {
+		for i := range args {
+			v, ok := g.Value(ft.In(i), rand)
+			if !ok {
+				v = reflect.Zero(ft.In(i))
+			}
+			args[i] = v
+		}
+	}
+	return &c
+}
+
+// fill sets the first n elements of the slice or array v.
+func (g Generator) fill(v reflect.Value, n int, rand *rand.Rand) bool {
+	for i := range n {
+		elem, ok := g.Value(v.Type().Elem(), rand)
+		if !ok {
+			return false
+		}
+		v.Index(i).Set(elem)
+	}
+	return true
+}
+
+// holdsOption reports whether values of t hold an `Option`, directly or
+// through their elements and fields.
+func holdsOption(t reflect.Type, seen []reflect.Type) bool {
+	if optreflect.Is(t) {
+		return true
+	}
+	for _, s := range seen {
+		if s == t {
+			return false
+		}
+	}
+	seen = append(seen, t)
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return holdsOption(t.Elem(), seen)
+	case reflect.Map:
+		return holdsOption(t.Key(), seen) || holdsOption(t.Elem(), seen)
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if holdsOption(t.Field(i).Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
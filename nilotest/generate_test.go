@@ -0,0 +1,105 @@
+package nilotest
+
+import (
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"github.com/javiorfo/nilo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerator(t *testing.T) {
+	count := func(g Generator) (values, nils int) {
+		r := rand.New(rand.NewSource(1))
+		for range 1000 {
+			v, ok := g.Value(reflect.TypeFor[nilo.Option[int]](), r)
+			assert.True(t, ok)
+			if v.Interface().(nilo.Option[int]).IsNil() {
+				nils++
+			} else {
+				values++
+			}
+		}
+		return values, nils
+	}
+
+	t.Run("default ratio", func(t *testing.T) {
+		values, nils := count(Generator{})
+
+		assert.InDelta(t, 1000*DefaultNilRatio, nils, 50)
+		assert.InDelta(t, 1000*(1-DefaultNilRatio), values, 50)
+	})
+
+	t.Run("only Nil", func(t *testing.T) {
+		_, nils := count(Generator{NilRatio: nilo.Value(1.0)})
+
+		assert.Equal(t, 1000, nils)
+	})
+
+	t.Run("never Nil", func(t *testing.T) {
+		values, _ := count(Generator{NilRatio: nilo.Value(0.0)})
+
+		assert.Equal(t, 1000, values)
+	})
+
+	t.Run("unsupported type is Nil", func(t *testing.T) {
+		v, ok := Generator{NilRatio: nilo.Value(0.0)}.Value(reflect.TypeFor[nilo.Option[chan int]](), rand.New(rand.NewSource(1)))
+
+		assert.True(t, ok)
+		assert.True(t, v.Interface().(nilo.Option[chan int]).IsNil())
+	})
+
+	t.Run("types holding Options", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1))
+		var emails, nils int
+		for range 100 {
+			v, ok := Generator{}.Value(reflect.TypeFor[[]account](), r)
+			assert.True(t, ok)
+			for _, a := range v.Interface().([]account) {
+				if a.Email.IsValue() {
+					emails++
+				} else {
+					nils++
+				}
+			}
+		}
+
+		assert.Positive(t, emails)
+		assert.Positive(t, nils)
+	})
+
+	t.Run("unexported fields cannot be generated", func(t *testing.T) {
+		type hidden struct {
+			email nilo.Option[string]
+		}
+		_, ok := Generator{}.Value(reflect.TypeFor[hidden](), rand.New(rand.NewSource(1)))
+
+		assert.False(t, ok)
+	})
+
+	t.Run("with quick.Check", func(t *testing.T) {
+		roundTrip := func(o nilo.Option[account]) bool {
+			data, err := json.Marshal(o)
+			if err != nil {
+				return false
+			}
+			var decoded nilo.Option[account]
+			return json.Unmarshal(data, &decoded) == nil && reflect.DeepEqual(o, decoded)
+		}
+
+		err := quick.Check(roundTrip, Generator{}.Config(roundTrip, nil))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("Config keeps the given Values", func(t *testing.T) {
+		values := func([]reflect.Value, *rand.Rand) {}
+		config := Generator{}.Config(func(nilo.Option[int]) bool { return true }, &quick.Config{MaxCount: 3, Values: values})
+
+		assert.Equal(t, 3, config.MaxCount)
+		assert.Equal(t, reflect.ValueOf(values).Pointer(), reflect.ValueOf(config.Values).Pointer())
+	})
+}
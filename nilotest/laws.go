@@ -0,0 +1,149 @@
+// Package nilotest provides helpers for testing code built on `nilo.Option`
+// that depend only on the standard library.
+//
+// The assertions report failures with readable `Option`s, as printed by
+// `nilo.Option.GoString`, instead of pointer addresses:
+//
+//	user := nilotest.RequireValue(t, repo.Find(id))
+//	nilotest.AssertValueEqual(t, user.Email, "ana@mail.com")
+//	nilotest.AssertNil(t, user.Phone)
+//
+// The law checks use `testing/quick` to verify, on arbitrary values of a
+// user type, that `Option` behaves as a lawful functor and monad:
+//
+//	func TestUserOption(t *testing.T) {
+//		nilotest.CheckMapLaws(t, normalize, capitalize, nil)
+//		nilotest.CheckJSONLaws[User](t, nil)
+//	}
+//
+// Arbitrary `Option`s are produced by `Generator`, which also makes
+// `quick.Check` work on functions taking `Option`s:
+//
+//	quick.Check(property, nilotest.Generator{}.Config(property, nil))
+package nilotest
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"github.com/javiorfo/nilo"
+)
+
+// CheckMapLaws checks the functor laws of `Map` for arbitrary `Option`s:
+//
+//   - identity: o.Map(id) equals o.
+//   - composition: o.Map(f).Map(g) equals o.Map(g∘f).
+//
+// Parameters:
+//   - t: The test reporting failures.
+//   - f: The first mapper to compose.
+//   - g: The second mapper to compose.
+//   - config: The `testing/quick` configuration; the defaults if nil.
+func CheckMapLaws[T any](t testing.TB, f, g func(T) T, config *quick.Config) {
+	t.Helper()
+	check(t, "Map identity", func(o nilo.Option[T]) bool {
+		return equal(o.Map(func(v T) T { return v }), o)
+	}, config)
+	check(t, "Map composition", func(o nilo.Option[T]) bool {
+		return equal(o.Map(f).Map(g), o.Map(func(v T) T { return g(f(v)) }))
+	}, config)
+}
+
+// CheckAndThenLaws checks the monad laws of `AndThen` for arbitrary values
+// and `Option`s:
+//
+//   - left identity: Value(x).AndThen(f) equals f(x).
+//   - right identity: o.AndThen(Value) equals o.
+//   - associativity: o.AndThen(f).AndThen(g) equals
+//     o.AndThen(func(x) { return f(x).AndThen(g) }).
+//
+// Parameters:
+//   - t: The test reporting failures.
+//   - f: The first function to chain.
+//   - g: The second function to chain.
+//   - config: The `testing/quick` configuration; the defaults if nil.
+func CheckAndThenLaws[T any](t testing.TB, f, g func(T) nilo.Option[T], config *quick.Config) {
+	t.Helper()
+	check(t, "AndThen left identity", func(x T) bool {
+		return equal(nilo.Value(x).AndThen(f), f(x))
+	}, config)
+	check(t, "AndThen right identity", func(o nilo.Option[T]) bool {
+		return equal(o.AndThen(nilo.Value[T]), o)
+	}, config)
+	check(t, "AndThen associativity", func(o nilo.Option[T]) bool {
+		return equal(o.AndThen(f).AndThen(g), o.AndThen(func(x T) nilo.Option[T] { return f(x).AndThen(g) }))
+	}, config)
+}
+
+// CheckFilterLaws checks the laws of `Filter` for arbitrary `Option`s:
+//
+//   - a predicate that always holds keeps o.
+//   - a predicate that never holds gives `Nil`.
+//   - o.Filter(p).Filter(q) equals o.Filter(p && q).
+//   - o.Filter(p) equals o.AndThen of a function returning `Nil` when p
+//     fails.
+//
+// Parameters:
+//   - t: The test reporting failures.
+//   - p: The first predicate.
+//   - q: The second predicate.
+//   - config: The `testing/quick` configuration; the defaults if nil.
+func CheckFilterLaws[T any](t testing.TB, p, q func(T) bool, config *quick.Config) {
+	t.Helper()
+	check(t, "Filter true", func(o nilo.Option[T]) bool {
+		return equal(o.Filter(func(T) bool { return true }), o)
+	}, config)
+	check(t, "Filter false", func(o nilo.Option[T]) bool {
+		return o.Filter(func(T) bool { return false }).IsNil()
+	}, config)
+	check(t, "Filter conjunction", func(o nilo.Option[T]) bool {
+		return equal(o.Filter(p).Filter(q), o.Filter(func(v T) bool { return p(v) && q(v) }))
+	}, config)
+	check(t, "Filter as AndThen", func(o nilo.Option[T]) bool {
+		return equal(o.Filter(p), o.AndThen(func(v T) nilo.Option[T] {
+			if p(v) {
+				return nilo.Value(v)
+			}
+			return nilo.Nil[T]()
+		}))
+	}, config)
+}
+
+// CheckJSONLaws checks that arbitrary `Option`s of T survive a round trip
+// through `MarshalJSON` and `UnmarshalJSON`, and that `Nil` is encoded as
+// `null`.
+//
+// Values of T whose JSON encoding is itself `null`, such as nil pointers,
+// decode as `Nil` and are reported as failures.
+//
+// Parameters:
+//   - t: The test reporting failures.
+//   - config: The `testing/quick` configuration; the defaults if nil.
+func CheckJSONLaws[T any](t testing.TB, config *quick.Config) {
+	t.Helper()
+	check(t, "JSON round trip", func(o nilo.Option[T]) bool {
+		data, err := o.MarshalJSON()
+		if err != nil {
+			return false
+		}
+		var decoded nilo.Option[T]
+		return decoded.UnmarshalJSON(data) == nil && equal(decoded, o)
+	}, config)
+	check(t, "JSON Nil", func(o nilo.Option[T]) bool {
+		data, err := json.Marshal(o)
+		return err == nil && o.IsNil() == (string(data) == "null")
+	}, config)
+}
+
+func check(t testing.TB, law string, property any, config *quick.Config) {
+	t.Helper()
+	if err := quick.Check(property, Generator{}.Config(property, config)); err != nil {
+		t.Errorf("nilotest: %s law does not hold: %v", law, err)
+	}
+}
+
+func equal[T any](a, b nilo.Option[T]) bool {
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,98 @@
+package nilotest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/javiorfo/nilo"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder captures the failures reported to it instead of failing the test.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+}
+
+type account struct {
+	ID    int
+	Owner string
+	Email nilo.Option[string]
+	Tags  []string
+}
+
+func TestCheckMapLaws(t *testing.T) {
+	t.Run("lawful", func(t *testing.T) {
+		CheckMapLaws(t, func(i int) int { return i * 2 }, func(i int) int { return i - 1 }, nil)
+		CheckMapLaws(t, strings.ToUpper, strings.TrimSpace, nil)
+		CheckMapLaws(t, func(a account) account { a.ID++; return a }, func(a account) account { a.Owner = ""; return a }, nil)
+	})
+
+	t.Run("reports failures", func(t *testing.T) {
+		r := &recorder{TB: t}
+		calls := 0
+		impure := func(i int) int { calls++; return i + calls }
+
+		CheckMapLaws(r, impure, impure, &quick.Config{MaxCount: 20})
+
+		assert.Len(t, r.failures, 1)
+		assert.Contains(t, r.failures[0], "Map composition")
+	})
+}
+
+func TestCheckAndThenLaws(t *testing.T) {
+	half := func(i int) nilo.Option[int] {
+		if i%2 != 0 {
+			return nilo.Nil[int]()
+		}
+		return nilo.Value(i / 2)
+	}
+	positive := func(i int) nilo.Option[int] {
+		if i <= 0 {
+			return nilo.Nil[int]()
+		}
+		return nilo.Value(i)
+	}
+
+	t.Run("lawful", func(t *testing.T) {
+		CheckAndThenLaws(t, half, positive, nil)
+		CheckAndThenLaws(t, func(a account) nilo.Option[account] {
+			return nilo.Value(a).Filter(func(a account) bool { return a.Email.IsValue() })
+		}, func(a account) nilo.Option[account] { return nilo.Value(a) }, nil)
+	})
+}
+
+func TestCheckFilterLaws(t *testing.T) {
+	CheckFilterLaws(t, func(i int) bool { return i > 0 }, func(i int) bool { return i%3 == 0 }, nil)
+	CheckFilterLaws(t, func(s string) bool { return s != "" }, func(s string) bool { return len(s) < 10 }, nil)
+}
+
+func TestCheckJSONLaws(t *testing.T) {
+	t.Run("lawful", func(t *testing.T) {
+		CheckJSONLaws[int](t, nil)
+		CheckJSONLaws[string](t, nil)
+		CheckJSONLaws[account](t, nil)
+	})
+
+	t.Run("reports failures", func(t *testing.T) {
+		r := &recorder{TB: t}
+
+		CheckJSONLaws[nilo.Option[bool]](r, &quick.Config{MaxCount: 200})
+
+		assert.Len(t, r.failures, 2)
+		assert.Contains(t, r.failures[0], "JSON round trip")
+		assert.Contains(t, r.failures[1], "JSON Nil")
+	})
+}
@@ -2,10 +2,10 @@
 package nilo
 
 import (
-	"fmt"
 	"iter"
 	"reflect"
-	"strconv"
+
+	"github.com/javiorfo/nilo/internal/cast"
 )
 
 // Option is a generic type that represents an option value.
@@ -278,7 +278,14 @@ func (o *Option[T]) Insert(value T) {
 
 // Cast attempts to assert the value V to type T.
 // If the type assertion is successful, it returns an Option containing the value.
-// If the assertion fails (e.g., incompatible types or i is nil), it returns a Nil Option.
+// Otherwise, numbers and booleans are formatted into strings, strings are parsed
+// into numbers and booleans, and Go's conversion rules are applied.
+// If every attempt fails (e.g., incompatible types or i is nil), it returns a Nil Option.
+//
+// Strings parse into every integer, unsigned integer and float size and into
+// `bool`. A string or number out of range of `T`, and NaN into an integer,
+// is `Nil` instead of wrapping. Any type whose underlying type is `string` is
+// a valid target.
 //
 // Example:
 //
@@ -288,41 +295,8 @@ func Cast[T any, V any](value V) Option[T] {
 		return Value(v)
 	}
 
-	val := reflect.ValueOf(value)
-	targetType := reflect.TypeFor[T]()
-
-	if targetType.Kind() == reflect.String {
-		var s string
-		switch val.Kind() {
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			s = strconv.FormatInt(val.Int(), 10)
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			s = strconv.FormatUint(val.Uint(), 10)
-		case reflect.Float32, reflect.Float64:
-			s = strconv.FormatFloat(val.Float(), 'f', -1, 64)
-		case reflect.Bool:
-			s = strconv.FormatBool(val.Bool())
-		default:
-			s = fmt.Sprint(value)
-		}
-		return Value(any(s).(T))
-	}
-
-	if s, ok := any(value).(string); ok {
-		switch targetType.Kind() {
-		case reflect.Int:
-			if i, err := strconv.Atoi(s); err == nil {
-				return Value(any(i).(T))
-			}
-		case reflect.Float64:
-			if f, err := strconv.ParseFloat(s, 64); err == nil {
-				return Value(any(f).(T))
-			}
-		}
-	}
-
-	if val.IsValid() && val.Type().ConvertibleTo(targetType) {
-		return Value(val.Convert(targetType).Interface().(T))
+	if v, ok := cast.To(value, reflect.TypeFor[T]()); ok {
+		return Value(v.Interface().(T))
 	}
 
 	return Nil[T]()
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"io"
+	"math"
 	"slices"
 	"testing"
 
@@ -447,6 +448,49 @@ func TestOption(t *testing.T) {
 				t.Error("Expected Some(Test), got Nil")
 			}
 		})
+
+		t.Run("Strings parse into every number size and bool", func(t *testing.T) {
+			assert.Equal(t, int8(-4), Cast[int8]("-4").AsValue())
+			assert.Equal(t, int64(1<<40), Cast[int64]("1099511627776").AsValue())
+			assert.Equal(t, uint16(4), Cast[uint16]("4").AsValue())
+			assert.Equal(t, float32(1.5), Cast[float32]("1.5").AsValue())
+			assert.Equal(t, true, Cast[bool]("true").AsValue())
+		})
+
+		t.Run("Strings out of range or malformed are Nil", func(t *testing.T) {
+			assert.True(t, Cast[int8]("300").IsNil())
+			assert.True(t, Cast[uint]("-1").IsNil())
+			assert.True(t, Cast[bool]("yes").IsNil())
+		})
+
+		t.Run("Named string targets", func(t *testing.T) {
+			type name string
+
+			assert.Equal(t, name("john"), Cast[name]("john").AsValue())
+			assert.Equal(t, name("42"), Cast[name](42).AsValue())
+		})
+
+		t.Run("nil is Nil", func(t *testing.T) {
+			assert.True(t, Cast[string](any(nil)).IsNil())
+			assert.True(t, Cast[string, error](nil).IsNil())
+		})
+
+		t.Run("Byte slices convert to their text", func(t *testing.T) {
+			assert.Equal(t, "abc", Cast[string]([]byte("abc")).AsValue())
+			assert.Equal(t, "ñu", Cast[string]([]rune("ñu")).AsValue())
+		})
+
+		t.Run("Numbers out of range are Nil", func(t *testing.T) {
+			assert.True(t, Cast[int8](300).IsNil())
+			assert.True(t, Cast[uint](-1).IsNil())
+			assert.True(t, Cast[int](math.NaN()).IsNil())
+			assert.True(t, Cast[int](1e30).IsNil())
+			assert.Equal(t, int8(-128), Cast[int8](-128).AsValue())
+		})
+
+		t.Run("float32 formats with its own precision", func(t *testing.T) {
+			assert.Equal(t, "0.1", Cast[string](float32(0.1)).AsValue())
+		})
 	})
 
 	t.Run("Iter", func(t *testing.T) {
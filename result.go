@@ -1,5 +1,13 @@
 package nilo
 
+import (
+	"database/sql"
+	"errors"
+	"io/fs"
+	"slices"
+	"sync"
+)
+
 // Ok creates an `Option` from a Go function's return values.
 //
 // It returns a `Value` `Option` containing `value` if `err` is `nil`.
@@ -58,3 +66,87 @@ func (o Option[T]) AndOkPtr(apply func(T) (*T, error)) Option[T] {
 	}
 	return Nil[T]()
 }
+
+// NotFoundClassifier reports whether an error means that a value is absent,
+// as opposed to a failure.
+type NotFoundClassifier func(err error) bool
+
+type registered struct {
+	id         int
+	classifier NotFoundClassifier
+}
+
+var (
+	classifiersMu sync.RWMutex
+	classifierID  int
+	classifiers   = []registered{
+		{classifier: func(err error) bool { return errors.Is(err, fs.ErrNotExist) }},
+		{classifier: func(err error) bool { return errors.Is(err, sql.ErrNoRows) }},
+		{classifier: func(err error) bool {
+			var status interface{ StatusCode() int }
+			return errors.As(err, &status) && status.StatusCode() == 404
+		}},
+	}
+)
+
+// RegisterNotFound adds a classifier used by `IsNotFound` and
+// `OkIfNotFound`, for absence errors of other packages, and returns a
+// function removing it again.
+//
+// The built-in classifiers recognize `fs.ErrNotExist`, `sql.ErrNoRows` and
+// errors with a `StatusCode() int` method returning `http.StatusNotFound`.
+//
+// Parameters:
+//   - classifier: A function reporting whether an error means absence.
+func RegisterNotFound(classifier NotFoundClassifier) (unregister func()) {
+	classifiersMu.Lock()
+	defer classifiersMu.Unlock()
+	classifierID++
+	id := classifierID
+	classifiers = append(classifiers, registered{id, classifier})
+
+	return func() {
+		classifiersMu.Lock()
+		defer classifiersMu.Unlock()
+		classifiers = slices.DeleteFunc(classifiers, func(r registered) bool { return r.id == id })
+	}
+}
+
+// IsNotFound reports whether err means that a value is absent, according
+// to the registered classifiers. A `nil` error is not an absence.
+func IsNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	classifiersMu.RLock()
+	defer classifiersMu.RUnlock()
+	for _, r := range classifiers {
+		if r.classifier(err) {
+			return true
+		}
+	}
+	return false
+}
+
+// OkIfNotFound creates an `Option` from a Go function's return values,
+// telling an absent value apart from a failure.
+//
+// Unlike `Ok`, which turns every error into `Nil`, it returns a `Nil` `Option`
+// and a `nil` error only for absence errors, as reported by `IsNotFound`.
+// Any other error is passed through, so a database outage no longer looks
+// like a missing row.
+//
+// Parameters:
+//   - value: The value to wrap in a `Value` `Option` if there is no error.
+//   - err: The error returned from a function.
+func OkIfNotFound[T any](value T, err error) (Option[T], error) {
+	switch {
+	case err == nil:
+		return Value(value), nil
+	case IsNotFound(err):
+		return Nil[T](), nil
+	default:
+		return Nil[T](), err
+	}
+}
@@ -1,7 +1,11 @@
 package nilo
 
 import (
+	"database/sql"
 	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -53,3 +57,60 @@ func TestResult(t *testing.T) {
 		})
 	})
 }
+
+type statusError int
+
+func (e statusError) Error() string {
+	return "status " + strconv.Itoa(int(e))
+}
+
+func (e statusError) StatusCode() int {
+	return int(e)
+}
+
+func TestNotFound(t *testing.T) {
+	t.Run("OkIfNotFound", func(t *testing.T) {
+		t.Run("when there is no error", func(t *testing.T) {
+			opt, err := OkIfNotFound(10, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, 10, opt.AsValue())
+		})
+
+		t.Run("when the error means absence", func(t *testing.T) {
+			for _, notFound := range []error{
+				fmt.Errorf("open config: %w", fs.ErrNotExist),
+				fmt.Errorf("find user: %w", sql.ErrNoRows),
+				fmt.Errorf("get user: %w", statusError(404)),
+			} {
+				opt, err := OkIfNotFound(10, notFound)
+				assert.NoError(t, err)
+				assert.True(t, opt.IsNil())
+			}
+		})
+
+		t.Run("when the error is a failure", func(t *testing.T) {
+			for _, failure := range []error{
+				errors.New("connection refused"),
+				statusError(500),
+			} {
+				opt, err := OkIfNotFound(10, failure)
+				assert.Equal(t, failure, err)
+				assert.True(t, opt.IsNil())
+			}
+		})
+	})
+
+	t.Run("RegisterNotFound", func(t *testing.T) {
+		errMissing := errors.New("cache miss")
+		assert.False(t, IsNotFound(errMissing))
+
+		unregister := RegisterNotFound(func(err error) bool { return errors.Is(err, errMissing) })
+		t.Cleanup(unregister)
+
+		assert.True(t, IsNotFound(fmt.Errorf("lookup: %w", errMissing)))
+		assert.False(t, IsNotFound(nil))
+
+		unregister()
+		assert.False(t, IsNotFound(errMissing))
+	})
+}
@@ -0,0 +1,31 @@
+package nilo
+
+import "log/slog"
+
+// LogValue implements the `slog.LogValuer` interface for `Option`.
+//
+// A `Nil` `Option` logs as a `nil` value, shown as `null` by
+// `slog.JSONHandler`. A `Value` `Option` logs as its contained value, so a
+// value that is itself a `slog.LogValuer` is resolved by its own
+// `LogValue` method instead of being printed through `String`.
+func (o Option[T]) LogValue() slog.Value {
+	if o.IsNil() {
+		return slog.AnyValue(nil)
+	}
+	return slog.AnyValue(*o.value)
+}
+
+// Attr returns a `slog.Attr` for the contained value of an `Option`.
+//
+// If the `Option` is `Nil`, it returns the empty `slog.Attr`, which handlers
+// drop, so the key is left out of the log record entirely.
+//
+// Parameters:
+//   - key: The attribute key.
+//   - opt: The `Option` holding the attribute value.
+func Attr[T any](key string, opt Option[T]) slog.Attr {
+	if opt.IsNil() {
+		return slog.Attr{}
+	}
+	return slog.Any(key, opt.LogValue())
+}
@@ -0,0 +1,88 @@
+package nilo
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type secret string
+
+func (secret) LogValue() slog.Value {
+	return slog.StringValue("REDACTED")
+}
+
+func TestLogValue(t *testing.T) {
+	newLogger := func(buf *bytes.Buffer) *slog.Logger {
+		return slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == slog.LevelKey) {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}))
+	}
+
+	t.Run("when Value", func(t *testing.T) {
+		var buf bytes.Buffer
+		newLogger(&buf).Info("user", "age", Value(30), "name", Value("Ana"))
+
+		assert.JSONEq(t, `{"msg":"user","age":30,"name":"Ana"}`, buf.String())
+	})
+
+	t.Run("when Nil", func(t *testing.T) {
+		var buf bytes.Buffer
+		newLogger(&buf).Info("user", "age", Nil[int]())
+
+		assert.JSONEq(t, `{"msg":"user","age":null}`, buf.String())
+	})
+
+	t.Run("when the value is a LogValuer", func(t *testing.T) {
+		var buf bytes.Buffer
+		newLogger(&buf).Info("login", "password", Value(secret("hunter2")))
+
+		assert.JSONEq(t, `{"msg":"login","password":"REDACTED"}`, buf.String())
+	})
+
+	t.Run("when nested", func(t *testing.T) {
+		assert.Equal(t, int64(3), Value(Value(3)).LogValue().Resolve().Int64())
+	})
+
+	t.Run("with TextHandler", func(t *testing.T) {
+		var buf bytes.Buffer
+		slog.New(slog.NewTextHandler(&buf, nil)).Info("user", "age", Value(30))
+
+		assert.Contains(t, buf.String(), "age=30")
+		assert.NotContains(t, buf.String(), "Value(")
+	})
+}
+
+func TestAttr(t *testing.T) {
+	t.Run("when Value", func(t *testing.T) {
+		attr := Attr("email", Value("ana@mail.com"))
+
+		assert.Equal(t, "email", attr.Key)
+		assert.Equal(t, "ana@mail.com", attr.Value.Resolve().String())
+	})
+
+	t.Run("when Nil", func(t *testing.T) {
+		attr := Attr("email", Nil[string]())
+
+		assert.True(t, attr.Equal(slog.Attr{}))
+
+		var buf bytes.Buffer
+		slog.New(slog.NewJSONHandler(&buf, nil)).LogAttrs(context.Background(), slog.LevelInfo, "user", attr, slog.Int("age", 30))
+		assert.NotContains(t, buf.String(), "email")
+		assert.Contains(t, buf.String(), `"age":30`)
+	})
+
+	t.Run("when the value is a LogValuer", func(t *testing.T) {
+		attr := Attr("password", Value(secret("hunter2")))
+
+		assert.Equal(t, "REDACTED", attr.Value.Resolve().String())
+	})
+}
@@ -0,0 +1,263 @@
+package sqlx
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/javiorfo/nilo"
+	"github.com/javiorfo/nilo/internal/optreflect"
+)
+
+// Dialect selects the placeholder style of a built query.
+type Dialect int
+
+const (
+	// Question uses `?` placeholders, as MySQL and SQLite do.
+	Question Dialect = iota
+	// Dollar uses `$1`, `$2`, ... placeholders, as PostgreSQL does.
+	Dollar
+)
+
+// Query builds a SELECT statement whose filters are dropped when their
+// `Option` arguments are `Nil`, which suits search endpoints with many
+// optional filters:
+//
+//	query, args, err := sqlx.Select("id", "name").From("users").
+//		Where("age >=", filters.MinAge).
+//		Where("name LIKE", filters.Name).
+//		OrderBy(filters.Sort, "name", "age").
+//		Limit(filters.Limit).
+//		Build(sqlx.Dollar)
+type Query struct {
+	columns []string
+	table   string
+	where   []clause
+	orderBy []order
+	limit   nilo.Option[int]
+	offset  nilo.Option[int]
+}
+
+type clause struct {
+	expr string
+	// parts holds the text around the placeholders of expr.
+	parts []string
+	args  []any
+	err   error
+}
+
+type order struct {
+	value   nilo.Option[string]
+	allowed []string
+}
+
+// Select starts a query selecting columns, or every column when none is
+// given.
+func Select(columns ...string) *Query {
+	return &Query{columns: columns}
+}
+
+// From sets the table, or any FROM expression such as a join.
+func (q *Query) From(table string) *Query {
+	q.table = table
+	return q
+}
+
+// Where adds a condition joined to the others with AND.
+//
+// The condition is dropped when any argument is a `Nil` `Option`; `Value`
+// `Option`s are unwrapped and other arguments are used as they are. If expr
+// holds no `?` placeholder, one is appended, so `Where("age >", minAge)`
+// renders as `age > ?`.
+//
+// A `?` inside a string literal or a quoted identifier is not a
+// placeholder, nor are the PostgreSQL `?|` and `?&` operators; the `?`
+// operator itself is written `??`.
+//
+// Parameters:
+//   - expr: The SQL condition, using `?` for each argument.
+//   - args: The arguments of the condition.
+func (q *Query) Where(expr string, args ...any) *Query {
+	values := make([]any, 0, len(args))
+	for _, arg := range args {
+		v := reflect.ValueOf(arg)
+		if !v.IsValid() || !optreflect.Is(v.Type()) {
+			values = append(values, arg)
+			continue
+		}
+		inner, ok := optreflect.Get(v)
+		if !ok {
+			return q
+		}
+		values = append(values, inner.Interface())
+	}
+
+	parts, err := split(expr)
+	if len(parts) == 1 && len(values) > 0 {
+		parts = []string{parts[0] + " ", ""}
+	}
+	q.where = append(q.where, clause{expr, parts, values, err})
+	return q
+}
+
+// OrderBy adds an ORDER BY term unless column is `Nil`.
+//
+// The value is written into the SQL, since placeholders cannot name
+// columns, so `Build` fails unless it is a plain or dotted identifier
+// optionally followed by `ASC` or `DESC`, such as `name` or `u.name DESC`.
+// When allowed is given, the column name must also be one of them.
+//
+// Parameters:
+//   - column: The column, optionally followed by `ASC` or `DESC`.
+//   - allowed: The column names the value may use.
+func (q *Query) OrderBy(column nilo.Option[string], allowed ...string) *Query {
+	q.orderBy = append(q.orderBy, order{column, allowed})
+	return q
+}
+
+// Limit sets the LIMIT clause unless n is `Nil`.
+func (q *Query) Limit(n nilo.Option[int]) *Query {
+	q.limit = n
+	return q
+}
+
+// Offset sets the OFFSET clause unless n is `Nil`.
+func (q *Query) Offset(n nilo.Option[int]) *Query {
+	q.offset = n
+	return q
+}
+
+// Build renders the query and its arguments with the placeholders of d,
+// numbered as each clause is written. It fails when a condition has more or
+// fewer placeholders than arguments or an unterminated quote, or when an ORDER BY term is invalid.
+func (q *Query) Build(d Dialect) (string, []any, error) {
+	var b strings.Builder
+	var args []any
+
+	b.WriteString("SELECT ")
+	if len(q.columns) == 0 {
+		b.WriteString("*")
+	} else {
+		b.WriteString(strings.Join(q.columns, ", "))
+	}
+	if q.table != "" {
+		b.WriteString(" FROM ")
+		b.WriteString(q.table)
+	}
+
+	n := 0
+	placeholder := func() {
+		n++
+		if d == Dollar {
+			b.WriteString("$" + strconv.Itoa(n))
+		} else {
+			b.WriteString("?")
+		}
+	}
+
+	for i, c := range q.where {
+		if c.err != nil {
+			return "", nil, c.err
+		}
+		if len(c.parts)-1 != len(c.args) {
+			return "", nil, fmt.Errorf("sqlx: condition %q has %d placeholders but %d arguments", c.expr, len(c.parts)-1, len(c.args))
+		}
+		if i == 0 {
+			b.WriteString(" WHERE ")
+		} else {
+			b.WriteString(" AND ")
+		}
+		if len(q.where) > 1 {
+			b.WriteString("(")
+		}
+		for j, part := range c.parts {
+			if j > 0 {
+				placeholder()
+			}
+			b.WriteString(part)
+		}
+		if len(q.where) > 1 {
+			b.WriteString(")")
+		}
+		args = append(args, c.args...)
+	}
+
+	var terms []string
+	for _, o := range q.orderBy {
+		if o.value.IsNil() {
+			continue
+		}
+		term := strings.TrimSpace(o.value.AsValue())
+		if err := checkOrder(term, o.allowed); err != nil {
+			return "", nil, err
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) > 0 {
+		b.WriteString(" ORDER BY ")
+		b.WriteString(strings.Join(terms, ", "))
+	}
+
+	if q.limit.IsValue() {
+		b.WriteString(" LIMIT ")
+		placeholder()
+		args = append(args, q.limit.AsValue())
+	}
+	if q.offset.IsValue() {
+		b.WriteString(" OFFSET ")
+		placeholder()
+		args = append(args, q.offset.AsValue())
+	}
+
+	return b.String(), args, nil
+}
+
+var orderTerm = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)*)(?:\s+(?i:(ASC|DESC)))?$`)
+
+func checkOrder(term string, allowed []string) error {
+	m := orderTerm.FindStringSubmatch(term)
+	if m == nil {
+		return fmt.Errorf("sqlx: invalid ORDER BY term %q", term)
+	}
+	if len(allowed) > 0 && !slices.Contains(allowed, m[1]) {
+		return fmt.Errorf("sqlx: ORDER BY column %q is not allowed", m[1])
+	}
+	return nil
+}
+
+// split splits expr around its `?` placeholders, skipping string literals,
+// quoted identifiers and the `?|` and `?&` operators, and unescaping `??`.
+func split(expr string) ([]string, error) {
+	var parts []string
+	var b strings.Builder
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			// Quotes are escaped by doubling them, which reads as two
+			// adjacent quoted sections.
+			end := strings.IndexByte(expr[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("sqlx: condition %q has an unterminated %c literal", expr, c)
+			}
+			b.WriteString(expr[i : i+end+2])
+			i += end + 1
+		case c != '?':
+			b.WriteByte(c)
+		case strings.HasPrefix(expr[i:], "??"):
+			b.WriteByte('?')
+			i++
+		case strings.HasPrefix(expr[i:], "?|") && !strings.HasPrefix(expr[i:], "?||"),
+			strings.HasPrefix(expr[i:], "?&") && !strings.HasPrefix(expr[i:], "?&&"):
+			b.WriteString(expr[i : i+2])
+			i++
+		default:
+			parts = append(parts, b.String())
+			b.Reset()
+		}
+	}
+	return append(parts, b.String()), nil
+}
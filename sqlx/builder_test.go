@@ -0,0 +1,122 @@
+package sqlx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/javiorfo/nilo"
+	"github.com/stretchr/testify/assert"
+)
+
+type filters struct {
+	MinAge nilo.Option[int]
+	Name   nilo.Option[string]
+	Sort   nilo.Option[string]
+	Limit  nilo.Option[int]
+	Offset nilo.Option[int]
+}
+
+func search(f filters) *Query {
+	return Select("id", "name").From("users").
+		Where("deleted_at IS NULL").
+		Where("age >=", f.MinAge).
+		Where("name LIKE", f.Name).
+		OrderBy(f.Sort, "name", "age").
+		Limit(f.Limit).
+		Offset(f.Offset)
+}
+
+func TestQuery(t *testing.T) {
+	t.Run("Nil filters are dropped", func(t *testing.T) {
+		query, args, err := search(filters{}).Build(Question)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "SELECT id, name FROM users WHERE deleted_at IS NULL", query)
+		assert.Empty(t, args)
+	})
+
+	t.Run("Value filters use question placeholders", func(t *testing.T) {
+		f := filters{MinAge: nilo.Value(18), Name: nilo.Value("jo%"), Sort: nilo.Value("age DESC"), Limit: nilo.Value(10)}
+
+		query, args, err := search(f).Build(Question)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "SELECT id, name FROM users WHERE (deleted_at IS NULL) AND (age >= ?) AND (name LIKE ?) ORDER BY age DESC LIMIT ?", query)
+		assert.Equal(t, []any{18, "jo%", 10}, args)
+	})
+
+	t.Run("Value filters use dollar placeholders", func(t *testing.T) {
+		f := filters{Name: nilo.Value("jo%"), Limit: nilo.Value(10), Offset: nilo.Value(20)}
+
+		query, args, err := search(f).Build(Dollar)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "SELECT id, name FROM users WHERE (deleted_at IS NULL) AND (name LIKE $1) LIMIT $2 OFFSET $3", query)
+		assert.Equal(t, []any{"jo%", 10, 20}, args)
+	})
+
+	t.Run("conditions with several arguments", func(t *testing.T) {
+		query, args, err := Select().From("users").
+			Where("age BETWEEN ? AND ?", nilo.Value(18), 65).
+			Where("name = ? OR nick = ?", nilo.Nil[string](), "x").
+			Build(Dollar)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "SELECT * FROM users WHERE age BETWEEN $1 AND $2", query)
+		assert.Equal(t, []any{18, 65}, args)
+	})
+
+	t.Run("ORDER BY is validated", func(t *testing.T) {
+		_, _, err := search(filters{Sort: nilo.Value("password")}).Build(Question)
+		assert.EqualError(t, err, `sqlx: ORDER BY column "password" is not allowed`)
+
+		_, _, err = search(filters{Sort: nilo.Value("name; DROP TABLE users")}).Build(Question)
+		assert.Error(t, err)
+	})
+
+	t.Run("ORDER BY without allowed columns takes identifiers only", func(t *testing.T) {
+		sorted := func(sort string) (string, error) {
+			query, _, err := Select().From("users u").OrderBy(nilo.Value(sort)).Build(Question)
+			return query, err
+		}
+
+		query, err := sorted("u.name desc")
+		assert.NoError(t, err)
+		assert.Equal(t, "SELECT * FROM users u ORDER BY u.name desc", query)
+
+		for _, sort := range []string{"(select pg_sleep(10))", "name--", "1", "name ASC, id", "name.", `"name"`, "name DESCENDING"} {
+			_, err := sorted(sort)
+			assert.EqualError(t, err, `sqlx: invalid ORDER BY term "`+strings.ReplaceAll(sort, `"`, `\"`)+`"`)
+		}
+	})
+
+	t.Run("quoted question marks and operators are not placeholders", func(t *testing.T) {
+		query, args, err := Select().From("docs").
+			Where(`title <> '?' AND "who?" = ?`, "me").
+			Where("data ?| array['a', 'b'] AND data ?& array['c']").
+			Where("data ?? 'key'").
+			Where("name || ? = ?", "x", nilo.Value("y")).
+			Limit(nilo.Value(5)).
+			Build(Dollar)
+
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT * FROM docs WHERE (title <> '?' AND "who?" = $1) AND (data ?| array['a', 'b'] AND data ?& array['c']) AND (data ? 'key') AND (name || $2 = $3) LIMIT $4`, query)
+		assert.Equal(t, []any{"me", "x", "y", 5}, args)
+
+		for _, expr := range []string{"name = 'abc", `"who? = ?`, "a = ? AND `b"} {
+			_, _, err := Select().From("docs").Where(expr, nilo.Value(1)).Build(Dollar)
+
+			assert.ErrorContains(t, err, "unterminated", expr)
+		}
+	})
+
+	t.Run("placeholder mismatch fails", func(t *testing.T) {
+		_, _, err := Select().From("users").Where("a = ? AND b = ?", 1).Build(Question)
+
+		assert.EqualError(t, err, `sqlx: condition "a = ? AND b = ?" has 2 placeholders but 1 arguments`)
+
+		query, _, err := Select().From("users").Where("note LIKE '%?%' AND a =", 1).Build(Question)
+		assert.NoError(t, err)
+		assert.Equal(t, "SELECT * FROM users WHERE note LIKE '%?%' AND a = ?", query)
+	})
+}
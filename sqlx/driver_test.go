@@ -0,0 +1,87 @@
+package sqlx
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+)
+
+// fakeDriver is an in-memory `database/sql/driver` whose queries are the
+// names of the datasets they return.
+type fakeDriver struct{}
+
+type dataset struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+var datasets = map[string]dataset{}
+
+func init() {
+	sql.Register("sqlxfake", fakeDriver{})
+}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return fakeConn{}, nil
+}
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) {
+	ds, ok := datasets[query]
+	if !ok {
+		return nil, errors.New("fake: unknown dataset " + query)
+	}
+	return fakeStmt{ds}, nil
+}
+
+func (fakeConn) Close() error {
+	return nil
+}
+
+func (fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("fake: transactions are not supported")
+}
+
+type fakeStmt struct {
+	ds dataset
+}
+
+func (fakeStmt) Close() error {
+	return nil
+}
+
+func (fakeStmt) NumInput() int {
+	return 0
+}
+
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("fake: exec is not supported")
+}
+
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &fakeRows{ds: s.ds}, nil
+}
+
+type fakeRows struct {
+	ds  dataset
+	pos int
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.ds.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.ds.rows) {
+		return io.EOF
+	}
+	copy(dest, r.ds.rows[r.pos])
+	r.pos++
+	return nil
+}
@@ -0,0 +1,291 @@
+// Package sqlx scans `database/sql` rows into structs, using `nilo.Option`
+// fields as the nullable targets.
+//
+// Columns are matched to struct fields by the `db` struct tag, or else by
+// the field name, case-insensitively or in snake case:
+//
+//	type User struct {
+//		ID    int64
+//		Name  string                 `db:"full_name"`
+//		Email nilo.Option[string]    // matches "email"
+//		Login nilo.Option[time.Time] `db:"last_login"`
+//	}
+//
+// A NULL column turns an `Option` field `Nil` and a pointer field nil. A
+// NULL landing in any other field is an error naming the column and field,
+// instead of a zero value silently passing for data.
+//
+// The package also offers `Query`, a SELECT builder that drops the filters
+// whose `Option` arguments are `Nil`.
+package sqlx
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/javiorfo/nilo/internal/cast"
+	"github.com/javiorfo/nilo/internal/fields"
+	"github.com/javiorfo/nilo/internal/optreflect"
+)
+
+const tagName = "db"
+
+// ErrNull is wrapped by the error reported when a NULL column is scanned
+// into a field that is neither an `Option` nor a pointer.
+var ErrNull = errors.New("NULL in a non-nullable field")
+
+// Rows is the subset of `*sql.Rows` used by this package.
+type Rows interface {
+	Columns() ([]string, error)
+	Next() bool
+	Scan(dest ...any) error
+	Err() error
+	Close() error
+}
+
+// ColumnError reports a column that could not be scanned into its field.
+type ColumnError struct {
+	// Column is the name of the column.
+	Column string
+	// Field is the Go name of the field, empty when no field matches.
+	Field string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *ColumnError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("sqlx: column %q: %v", e.Column, e.Err)
+	}
+	return fmt.Sprintf("sqlx: column %q into field %s: %v", e.Column, e.Field, e.Err)
+}
+
+func (e *ColumnError) Unwrap() error {
+	return e.Err
+}
+
+// ScanStruct scans the current row of rows into the struct pointed to by
+// dst. Every column must match a field.
+func ScanStruct(rows Rows, dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("sqlx: ScanStruct requires a non-nil pointer to a struct")
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	targets, err := match(rv.Elem().Type(), columns)
+	if err != nil {
+		return err
+	}
+	return scan(rows, rv.Elem(), columns, targets)
+}
+
+// ScanAll scans every remaining row of rows into a `T`, which must be a
+// struct type, and closes rows.
+func ScanAll[T any](rows Rows) ([]T, error) {
+	defer rows.Close()
+
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("sqlx: ScanAll requires a struct type, got %s", t)
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	targets, err := match(t, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []T
+	for rows.Next() {
+		var row T
+		if err := scan(rows, reflect.ValueOf(&row).Elem(), columns, targets); err != nil {
+			return result, err
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// match returns the field of t for each column.
+func match(t reflect.Type, columns []string) ([]fields.Field, error) {
+	fs := fields.Of(t, tagName)
+	targets := make([]fields.Field, len(columns))
+	for i, column := range columns {
+		f, ok := lookup(fs, column)
+		if !ok {
+			return nil, &ColumnError{Column: column, Err: fmt.Errorf("no matching field in %s", t)}
+		}
+		targets[i] = f
+	}
+	return targets, nil
+}
+
+func lookup(fs []fields.Field, column string) (fields.Field, bool) {
+	for _, f := range fs {
+		if f.Tagged && f.Name == column {
+			return f, true
+		}
+	}
+	for _, f := range fs {
+		if !f.Tagged && (strings.EqualFold(f.Name, column) || snakeCase(f.Name) == column) {
+			return f, true
+		}
+	}
+	return fields.Field{}, false
+}
+
+func scan(rows Rows, v reflect.Value, columns []string, targets []fields.Field) error {
+	raw := make([]any, len(columns))
+	dest := make([]any, len(columns))
+	for i := range raw {
+		dest[i] = &raw[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+
+	for i, f := range targets {
+		if err := assign(v.FieldByIndex(f.Index), raw[i]); err != nil {
+			return &ColumnError{Column: columns[i], Field: f.GoName, Err: err}
+		}
+	}
+	return nil
+}
+
+var scannerType = reflect.TypeFor[sql.Scanner]()
+
+// assign stores the driver value src in v.
+func assign(v reflect.Value, src any) error {
+	t := v.Type()
+	if reflect.PointerTo(t).Implements(scannerType) {
+		return v.Addr().Interface().(sql.Scanner).Scan(src)
+	}
+
+	if src == nil {
+		switch {
+		case optreflect.Is(t), t.Kind() == reflect.Pointer, t.Kind() == reflect.Interface:
+			v.Set(reflect.Zero(t))
+			return nil
+		default:
+			return ErrNull
+		}
+	}
+
+	switch {
+	case optreflect.Is(t):
+		inner := reflect.New(optreflect.Elem(t)).Elem()
+		if err := assign(inner, src); err != nil {
+			return err
+		}
+		optreflect.Set(v, inner)
+		return nil
+	case t.Kind() == reflect.Pointer:
+		elem := reflect.New(t.Elem())
+		if err := assign(elem.Elem(), src); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+
+	return convert(v, src)
+}
+
+// convert stores the non-nil driver value src, one of `int64`, `float64`,
+// `bool`, `[]byte`, `string` or `time.Time`, in v.
+func convert(v reflect.Value, src any) error {
+	t := v.Type()
+	if b, ok := src.([]byte); ok {
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			v.SetBytes(append([]byte{}, b...))
+			return nil
+		}
+		src = string(b)
+	}
+
+	sv := reflect.ValueOf(src)
+	if sv.Type().ConvertibleTo(t) && sv.Kind() == t.Kind() {
+		v.Set(sv.Convert(t))
+		return nil
+	}
+
+	switch n := src.(type) {
+	case string:
+		c, err := cast.FromText(n, t)
+		if err != nil {
+			return err
+		}
+		v.Set(c)
+		return nil
+	case int64:
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if !v.OverflowInt(n) {
+				v.SetInt(n)
+				return nil
+			}
+			return fmt.Errorf("value %d overflows %s", n, t)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if n >= 0 && !v.OverflowUint(uint64(n)) {
+				v.SetUint(uint64(n))
+				return nil
+			}
+			return fmt.Errorf("value %d overflows %s", n, t)
+		case reflect.Float32, reflect.Float64:
+			v.SetFloat(float64(n))
+			return nil
+		case reflect.Bool:
+			v.SetBool(n != 0)
+			return nil
+		}
+	case float64:
+		switch t.Kind() {
+		case reflect.Float32:
+			v.SetFloat(n)
+			return nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			// Converting a float out of the int64 range is implementation
+			// defined, so the range is checked on the float first. The
+			// float64 bounds are -2^63 and 2^63.
+			if n == math.Trunc(n) && n >= math.MinInt64 && n < math.MaxInt64 && !v.OverflowInt(int64(n)) {
+				v.SetInt(int64(n))
+				return nil
+			}
+			return fmt.Errorf("value %v does not fit %s", n, t)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if n == math.Trunc(n) && n >= 0 && n < math.MaxUint64 && !v.OverflowUint(uint64(n)) {
+				v.SetUint(uint64(n))
+				return nil
+			}
+			return fmt.Errorf("value %v does not fit %s", n, t)
+		}
+	}
+	return fmt.Errorf("cannot convert %T to %s", src, t)
+}
+
+func snakeCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,157 @@
+package sqlx
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/javiorfo/nilo"
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	ID        int64
+	Name      string `db:"full_name"`
+	Email     nilo.Option[string]
+	Age       nilo.Option[int]
+	LastLogin nilo.Option[time.Time]
+	Score     *float64
+	Nick      sql.NullString
+}
+
+func query(t *testing.T, name string, ds dataset) *sql.Rows {
+	t.Helper()
+	datasets[name] = ds
+
+	db, err := sql.Open("sqlxfake", "")
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	rows, err := db.Query(name)
+	assert.NoError(t, err)
+	return rows
+}
+
+func TestScan(t *testing.T) {
+	login := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	columns := []string{"id", "full_name", "email", "age", "last_login", "score", "nick"}
+
+	t.Run("ScanAll", func(t *testing.T) {
+		t.Run("maps NULL to Nil", func(t *testing.T) {
+			rows := query(t, "users", dataset{columns, [][]driver.Value{
+				{int64(1), "John", "john@mail.com", int64(30), login, 9.5, "jj"},
+				{int64(2), []byte("Jane"), nil, nil, nil, nil, nil},
+			}})
+
+			result, err := ScanAll[user](rows)
+
+			assert.NoError(t, err)
+			assert.Len(t, result, 2)
+			assert.Equal(t, "John", result[0].Name)
+			assert.Equal(t, "john@mail.com", result[0].Email.AsValue())
+			assert.Equal(t, 30, result[0].Age.AsValue())
+			assert.Equal(t, login, result[0].LastLogin.AsValue())
+			assert.Equal(t, 9.5, *result[0].Score)
+			assert.Equal(t, "jj", result[0].Nick.String)
+
+			assert.Equal(t, "Jane", result[1].Name)
+			assert.True(t, result[1].Email.IsNil())
+			assert.True(t, result[1].Age.IsNil())
+			assert.True(t, result[1].LastLogin.IsNil())
+			assert.Nil(t, result[1].Score)
+			assert.False(t, result[1].Nick.Valid)
+		})
+
+		t.Run("converts text columns", func(t *testing.T) {
+			rows := query(t, "text", dataset{[]string{"ID", "age", "last_login"}, [][]driver.Value{
+				{"7", []byte("41"), "2024-01-02T03:04:05Z"},
+			}})
+
+			result, err := ScanAll[user](rows)
+
+			assert.NoError(t, err)
+			assert.Equal(t, int64(7), result[0].ID)
+			assert.Equal(t, 41, result[0].Age.AsValue())
+			assert.Equal(t, login, result[0].LastLogin.AsValue())
+		})
+
+		t.Run("NULL in a non-Option field fails", func(t *testing.T) {
+			rows := query(t, "null", dataset{[]string{"id", "full_name"}, [][]driver.Value{
+				{int64(1), nil},
+			}})
+
+			_, err := ScanAll[user](rows)
+
+			assert.ErrorIs(t, err, ErrNull)
+			assert.EqualError(t, err, `sqlx: column "full_name" into field Name: NULL in a non-nullable field`)
+		})
+
+		t.Run("unmatched columns fail", func(t *testing.T) {
+			rows := query(t, "unmatched", dataset{[]string{"id", "password"}, nil})
+
+			_, err := ScanAll[user](rows)
+
+			var columnErr *ColumnError
+			assert.ErrorAs(t, err, &columnErr)
+			assert.Equal(t, "password", columnErr.Column)
+		})
+
+		t.Run("overflow fails", func(t *testing.T) {
+			rows := query(t, "overflow", dataset{[]string{"age"}, [][]driver.Value{{1.5}}})
+
+			_, err := ScanAll[user](rows)
+
+			assert.ErrorContains(t, err, `sqlx: column "age" into field Age`)
+		})
+	})
+
+	t.Run("ScanStruct", func(t *testing.T) {
+		rows := query(t, "one", dataset{[]string{"id", "email"}, [][]driver.Value{{int64(3), nil}}})
+		defer rows.Close()
+		result := user{Email: nilo.Value("previous")}
+
+		assert.True(t, rows.Next())
+		err := ScanStruct(rows, &result)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), result.ID)
+		assert.True(t, result.Email.IsNil())
+		assert.Error(t, ScanStruct(rows, result))
+	})
+
+	t.Run("convert floats", func(t *testing.T) {
+		var i int64
+		assert.NoError(t, convert(reflect.ValueOf(&i).Elem(), 42.0))
+		assert.Equal(t, int64(42), i)
+		assert.EqualError(t, convert(reflect.ValueOf(&i).Elem(), 1e20), "value 1e+20 does not fit int64")
+		assert.EqualError(t, convert(reflect.ValueOf(&i).Elem(), math.Pow(2, 63)), "value 9.223372036854776e+18 does not fit int64")
+		assert.NoError(t, convert(reflect.ValueOf(&i).Elem(), -math.Pow(2, 63)))
+		assert.Equal(t, int64(math.MinInt64), i)
+
+		var u uint8
+		assert.NoError(t, convert(reflect.ValueOf(&u).Elem(), 200.0))
+		assert.Equal(t, uint8(200), u)
+		assert.EqualError(t, convert(reflect.ValueOf(&u).Elem(), 300.0), "value 300 does not fit uint8")
+		assert.EqualError(t, convert(reflect.ValueOf(&u).Elem(), -1.0), "value -1 does not fit uint8")
+
+		var u64 uint64
+		assert.EqualError(t, convert(reflect.ValueOf(&u64).Elem(), 1e20), "value 1e+20 does not fit uint64")
+	})
+
+	t.Run("ErrNoRows is an absence", func(t *testing.T) {
+		opt, err := nilo.OkIfNotFound(user{}, fmt.Errorf("find user: %w", sql.ErrNoRows))
+
+		assert.NoError(t, err)
+		assert.True(t, opt.IsNil())
+	})
+
+	t.Run("snakeCase", func(t *testing.T) {
+		assert.Equal(t, "last_login", snakeCase("LastLogin"))
+		assert.Equal(t, "user_id", snakeCase("UserID"))
+		assert.Equal(t, "http_server", snakeCase("HTTPServer"))
+	})
+}
@@ -0,0 +1,99 @@
+// Package tmplx provides `text/template` and `html/template` functions
+// for templates rendering `nilo.Option` fields of any type:
+//
+//	tmpl := template.New("email").Funcs(tmplx.Funcs())
+//
+// Templates can also call the non-generic `Option` methods directly, as in
+// `{{ .Email.Or "n/a" }}`, `{{ if .Email.IsValue }}` or
+// `{{ .Age.OrDefault }}`.
+package tmplx
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/javiorfo/nilo/internal/optreflect"
+)
+
+// Funcs returns the template functions:
+//
+//   - isValue: reports whether an `Option` is `Value`.
+//   - isNil: reports whether an `Option` is `Nil`.
+//   - coalesce: returns the first argument holding a value, unwrapping
+//     `Option`s, or nil when there is none. Unlike the builtin `or`, a
+//     `Value` holding a zero value is returned.
+//   - orDefault: returns the contained value or the default of `OrDefault`.
+//   - unwrap: returns the contained value, failing the execution if `Nil`.
+//
+// A nil argument is treated as `Nil` and any other non-`Option` argument as
+// a value. The result is a plain map so it can be passed to the `Funcs`
+// method of both template packages.
+func Funcs() map[string]any {
+	return map[string]any{
+		"isValue": func(x any) bool {
+			_, ok := held(x)
+			return ok
+		},
+		"isNil": func(x any) bool {
+			_, ok := held(x)
+			return !ok
+		},
+		"coalesce":  coalesce,
+		"orDefault": orDefault,
+		"unwrap":    unwrap,
+	}
+}
+
+// held returns the value held by x, unwrapping `Option`s and
+// pointers to them, and whether there is one.
+func held(x any) (any, bool) {
+	v := reflect.ValueOf(x)
+	if !v.IsValid() {
+		return nil, false
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, false
+		}
+		if optreflect.Is(v.Type().Elem()) {
+			v = v.Elem()
+		}
+	}
+	if !optreflect.Is(v.Type()) {
+		return x, true
+	}
+	elem, ok := optreflect.Get(v)
+	if !ok {
+		return nil, false
+	}
+	return elem.Interface(), true
+}
+
+func coalesce(first any, rest ...any) any {
+	for _, x := range append([]any{first}, rest...) {
+		if v, ok := held(x); ok {
+			return v
+		}
+	}
+	return nil
+}
+
+func orDefault(x any) any {
+	v := reflect.ValueOf(x)
+	if v.IsValid() && v.Kind() == reflect.Pointer && !v.IsNil() && optreflect.Is(v.Type().Elem()) {
+		v = v.Elem()
+	}
+	if v.IsValid() && optreflect.Is(v.Type()) {
+		return v.MethodByName("OrDefault").Call(nil)[0].Interface()
+	}
+	value, _ := held(x)
+	return value
+}
+
+func unwrap(x any) (any, error) {
+	value, ok := held(x)
+	if !ok {
+		return nil, errors.New("unwrap: Option value is Nil")
+	}
+	return value, nil
+}
@@ -0,0 +1,105 @@
+package tmplx
+
+import (
+	htmltemplate "html/template"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/javiorfo/nilo"
+	"github.com/stretchr/testify/assert"
+)
+
+type templateUser struct {
+	Name     string
+	Email    nilo.Option[string]
+	Phone    nilo.Option[string]
+	Age      nilo.Option[int]
+	Nickname *nilo.Option[string]
+	Bio      nilo.Option[string]
+}
+
+func TestFuncs(t *testing.T) {
+	nickname := nilo.Value("ana")
+	user := templateUser{
+		Name:     "Ana",
+		Email:    nilo.Value("ana@mail.com"),
+		Phone:    nilo.Nil[string](),
+		Age:      nilo.Nil[int](),
+		Nickname: &nickname,
+		Bio:      nilo.Value("<b>hi</b>"),
+	}
+
+	render := func(t *testing.T, text string, data any) (string, error) {
+		t.Helper()
+		tmpl, err := template.New("test").Funcs(Funcs()).Parse(text)
+		if err != nil {
+			return "", err
+		}
+		var sb strings.Builder
+		err = tmpl.Execute(&sb, data)
+		return sb.String(), err
+	}
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"isValue", `{{ isValue .Email }} {{ isValue .Phone }}`, "true false"},
+		{"isNil", `{{ isNil .Email }} {{ isNil .Phone }}`, "false true"},
+		{"isValue with pointer", `{{ isValue .Nickname }}`, "true"},
+		{"coalesce with Value", `{{ coalesce .Email "n/a" }}`, "ana@mail.com"},
+		{"coalesce with Nil", `{{ coalesce .Phone "n/a" }}`, "n/a"},
+		{"coalesce with several Options", `{{ coalesce .Phone .Email "n/a" }}`, "ana@mail.com"},
+		{"coalesce keeps zero values", `{{ coalesce .Phone "" "x" }}|{{ coalesce (.Age.Or 0) 18 }}`, "|0"},
+		{"coalesce of Nils", `{{ coalesce .Age 18 }}|{{ coalesce .Phone .Age }}`, "18|<no value>"},
+		{"builtin or is untouched", `{{ or "" 0 "x" }}|{{ or .Phone "n/a" }}`, "x|Nil"},
+		{"orDefault", `{{ orDefault .Age }} {{ orDefault .Email }}`, "0 ana@mail.com"},
+		{"unwrap", `{{ unwrap .Email }} {{ unwrap .Nickname }}`, "ana@mail.com ana"},
+		{"if", `{{ if isValue .Phone }}{{ unwrap .Phone }}{{ else }}no phone{{ end }}`, "no phone"},
+		{"with", `{{ with unwrap .Email }}<{{ . }}>{{ end }}`, "<ana@mail.com>"},
+		{"methods", `{{ .Email.Or "n/a" }} {{ .Phone.Or "n/a" }} {{ .Age.OrDefault }} {{ .Phone.IsNil }}`, "ana@mail.com n/a 0 true"},
+		{"method on pointer", `{{ .Nickname.Or "-" }}`, "ana"},
+		{"print", `{{ .Email }} {{ .Phone }}`, "Value(ana@mail.com) Nil"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := render(t, tt.text, user)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	t.Run("unwrap fails on Nil", func(t *testing.T) {
+		_, err := render(t, `{{ unwrap .Phone }}`, user)
+
+		assert.ErrorContains(t, err, "Option value is Nil")
+	})
+
+	t.Run("AsValue fails on Nil", func(t *testing.T) {
+		_, err := render(t, `{{ .Phone.AsValue }}`, user)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("nil arguments are Nil", func(t *testing.T) {
+		result, err := render(t, `{{ isNil .Missing }} {{ isNil . }}`, map[string]any{"Missing": nil})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "true false", result)
+	})
+
+	t.Run("html/template", func(t *testing.T) {
+		tmpl := htmltemplate.Must(htmltemplate.New("email").Funcs(Funcs()).Parse(
+			`<p>{{ .Name }}</p><p>{{ coalesce .Phone "n/a" }}</p><p>{{ unwrap .Bio }}</p><a href="mailto:{{ .Email.Or "" }}">{{ .Email.Or "no email" }}</a>`))
+
+		var sb strings.Builder
+		err := tmpl.Execute(&sb, user)
+
+		assert.NoError(t, err)
+		assert.Equal(t, `<p>Ana</p><p>n/a</p><p>&lt;b&gt;hi&lt;/b&gt;</p><a href="mailto:ana@mail.com">ana@mail.com</a>`, sb.String())
+	})
+}
@@ -0,0 +1,42 @@
+//go:build nilo_yaml
+
+package nilo
+
+import "gopkg.in/yaml.v3"
+
+// MarshalYAML implements the `yaml.Marshaler` interface for `Option`.
+//
+// If the `Option` is `Nil`, it marshals to the YAML value `null`.
+// If the `Option` is `Value`, it marshals the wrapped value to its YAML representation.
+//
+// This file is only compiled with the `nilo_yaml` build tag, so the
+// `gopkg.in/yaml.v3` dependency is opt-in.
+func (o Option[T]) MarshalYAML() (any, error) {
+	if o.IsNil() {
+		return nil, nil
+	}
+	return o.AsValue(), nil
+}
+
+// UnmarshalYAML implements the `yaml.Unmarshaler` interface for `Option`.
+//
+// If the YAML node is `null` or `~`, it unmarshals into a `Nil` `Option`.
+// Otherwise, it decodes the node into the `Option`'s value, creating a
+// `Value` `Option` with the unmarshaled content.
+//
+// Note that `yaml.v3` does not call unmarshalers for `null` values inside
+// documents; such fields are left untouched, so fresh `Option`s stay `Nil`.
+func (o *Option[T]) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null" {
+		o.value = nil
+		return nil
+	}
+
+	var v T
+	if err := node.Decode(&v); err != nil {
+		return err
+	}
+
+	o.value = &v
+	return nil
+}
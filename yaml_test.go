@@ -0,0 +1,96 @@
+//go:build nilo_yaml
+
+package nilo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestYAML(t *testing.T) {
+	type config struct {
+		Name    Option[string]         `yaml:"name"`
+		Port    Option[int]            `yaml:"port"`
+		Labels  Option[[]string]       `yaml:"labels"`
+		Nested  Option[Option[string]] `yaml:"nested"`
+		Missing Option[string]         `yaml:"missing"`
+	}
+
+	t.Run("Marshal", func(t *testing.T) {
+		t.Run("MarshalYAML on a Value Option", func(t *testing.T) {
+			result, err := yaml.Marshal(Value("hello"))
+
+			assert.NoError(t, err)
+			assert.Equal(t, "hello\n", string(result))
+		})
+
+		t.Run("MarshalYAML on a Nil Option", func(t *testing.T) {
+			result, err := yaml.Marshal(Nil[string]())
+
+			assert.NoError(t, err)
+			assert.Equal(t, "null\n", string(result))
+		})
+	})
+
+	t.Run("Unmarshal", func(t *testing.T) {
+		t.Run("UnmarshalYAML from null and ~", func(t *testing.T) {
+			var result config
+			data := "name: null\nport: ~\n"
+
+			err := yaml.Unmarshal([]byte(data), &result)
+
+			assert.NoError(t, err)
+			assert.True(t, result.Name.IsNil())
+			assert.True(t, result.Port.IsNil())
+			assert.True(t, result.Missing.IsNil())
+		})
+
+		t.Run("UnmarshalYAML from a null node resets the Option", func(t *testing.T) {
+			opt := Value("previous")
+			node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "~"}
+
+			err := opt.UnmarshalYAML(node)
+
+			assert.NoError(t, err)
+			assert.True(t, opt.IsNil())
+		})
+
+		t.Run("UnmarshalYAML from valid values", func(t *testing.T) {
+			var result config
+			data := "name: api\nport: 8080\nlabels: [a, b]\nnested: inner\n"
+
+			err := yaml.Unmarshal([]byte(data), &result)
+
+			assert.NoError(t, err)
+			assert.Equal(t, "api", result.Name.AsValue())
+			assert.Equal(t, 8080, result.Port.AsValue())
+			assert.Equal(t, []string{"a", "b"}, result.Labels.AsValue())
+			assert.Equal(t, "inner", result.Nested.AsValue().AsValue())
+		})
+
+		t.Run("UnmarshalYAML with invalid data returns an error", func(t *testing.T) {
+			var result config
+			data := "port: not-a-number\n"
+
+			err := yaml.Unmarshal([]byte(data), &result)
+
+			assert.Error(t, err)
+			assert.True(t, result.Port.IsNil())
+		})
+	})
+
+	t.Run("Round trip", func(t *testing.T) {
+		input := config{Name: Value("api"), Labels: Value([]string{"x"})}
+		var result config
+
+		data, err := yaml.Marshal(input)
+		assert.NoError(t, err)
+
+		err = yaml.Unmarshal(data, &result)
+
+		assert.NoError(t, err)
+		assert.Equal(t, input, result)
+	})
+}